@@ -0,0 +1,410 @@
+package createsetters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// defaultMinOccurrences is the number of resources a value must appear in
+// before it is proposed as a setter
+const defaultMinOccurrences = 2
+
+// AutoSetters configures the automatic discovery of setter candidates
+type AutoSetters struct {
+	// Enabled turns on the discovery of setter candidates
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Apply adds the setter comments for the discovered candidates,
+	// by default the candidates are only reported
+	Apply bool `yaml:"apply,omitempty"`
+
+	// MinOccurrences is the minimum number of resources a value must
+	// appear in to be proposed as a setter, defaults to 2
+	MinOccurrences int `yaml:"minOccurrences,omitempty"`
+}
+
+// Candidate is a setter proposed by the automatic discovery
+type Candidate struct {
+	// Name is the suggested name of the setter
+	Name string
+
+	// Value is the value of the fields to be parameterized
+	Value string
+
+	// Reason describes why the value was proposed
+	Reason string
+
+	// Count is the number of occurrences of the value
+	Count int
+
+	// FieldPath is the path of the first field the value is discovered in
+	FieldPath string
+
+	// FilePath is the file path of the resource of the first field
+	FilePath string
+}
+
+// wellKnownField maps a field path to the suggested setter name
+type wellKnownField struct {
+	// pattern matches the field path
+	pattern *regexp.Regexp
+
+	// reason describes the field
+	reason string
+
+	// name returns the suggested setter name and the value to be
+	// parameterized, ok is false if the field should not be proposed
+	name func(resourceName, fieldPath, value string) (name, setterValue string, ok bool)
+}
+
+var wellKnownFields = []wellKnownField{
+	{
+		pattern: regexp.MustCompile(`(^|\.)(initContainers|containers)\[\d+\]\.image$`),
+		reason:  "image tag",
+		name: func(_, _, value string) (string, string, bool) {
+			repo, tag := splitImageTag(value)
+			if tag == "" {
+				return "", "", false
+			}
+			return repo[strings.LastIndex(repo, "/")+1:] + "-tag", tag, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`^metadata\.namespace$`),
+		reason:  "namespace",
+		name: func(_, _, value string) (string, string, bool) {
+			return "namespace", value, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`^spec\.replicas$`),
+		reason:  "replica count",
+		name: func(resourceName, _, value string) (string, string, bool) {
+			return resourceName + "-replicas", value, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`resources\.limits\.(cpu|memory)$`),
+		reason:  "resource limit",
+		name: func(resourceName, fieldPath, value string) (string, string, bool) {
+			resource := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+			return fmt.Sprintf("%s-%s-limit", resourceName, resource), value, true
+		},
+	},
+}
+
+// nameFieldPattern matches the fields in which the names of the resources
+// are proposed as setters, e.g. the labels and selectors referring to them
+var nameFieldPattern = regexp.MustCompile(`^metadata\.name$|(^|\.)(labels|matchLabels)\.|^spec\.selector\.`)
+
+// discovered is a candidate found in a field of a resource
+type discovered struct {
+	Candidate
+
+	// location is the location of the field, see location
+	location string
+}
+
+// discoveryVisitor collects the setter candidates of a single resource
+type discoveryVisitor struct {
+	// resourceName is the name of the resource being visited
+	resourceName string
+
+	// resource is the index of the resource being visited
+	resource int
+
+	// wellKnown holds the candidates found in well-known fields
+	wellKnown []discovered
+
+	// values holds the field paths of the string values found in the resource
+	values map[string][]string
+}
+
+func (dv *discoveryVisitor) visitMapping(_ *yaml.RNode, _ string) error {
+	return nil
+}
+
+func (dv *discoveryVisitor) visitScalar(object *yaml.RNode, path string) error {
+	node := object.YNode()
+	fieldPath := strings.TrimPrefix(path, ".")
+	if !isCandidateField(node, fieldPath) {
+		return nil
+	}
+	for _, f := range wellKnownFields {
+		if !f.pattern.MatchString(fieldPath) {
+			continue
+		}
+		if name, value, ok := f.name(dv.resourceName, fieldPath, node.Value); ok {
+			dv.wellKnown = append(dv.wellKnown, discovered{
+				Candidate: Candidate{Name: name, Value: value, Reason: f.reason, Count: 1},
+				location:  location(dv.resource, fieldPath),
+			})
+		}
+		return nil
+	}
+	if !yaml.IsYNodeString(node) || len(node.Value) < 3 {
+		return nil
+	}
+	dv.values[node.Value] = append(dv.values[node.Value], fieldPath)
+	return nil
+}
+
+// location identifies the field of the resource at input index in the package,
+// the candidates are only applied to the locations they are discovered in
+func location(resource int, fieldPath string) string {
+	return fmt.Sprintf("%d:%s", resource, strings.TrimPrefix(fieldPath, "."))
+}
+
+// locationFieldPath returns the field path of the location
+func locationFieldPath(l string) string {
+	return l[strings.Index(l, ":")+1:]
+}
+
+// countResources returns the number of resources of the locations
+func countResources(locations []string) int {
+	resources := map[string]bool{}
+	for _, l := range locations {
+		resources[l[:strings.Index(l, ":")]] = true
+	}
+	return len(resources)
+}
+
+// isCandidateField checks if the field may be parameterized, skipping
+// fields which are already parameterized and fields which identify the type
+func isCandidateField(node *yaml.Node, fieldPath string) bool {
+	if node.Value == "" || hasMultipleLines(node.Value) {
+		return false
+	}
+	if strings.Contains(node.LineComment, "kpt-set:") || strings.Contains(node.Value, "${") {
+		return false
+	}
+	if fieldPath == "apiVersion" || fieldPath == "kind" {
+		return false
+	}
+	// skip the annotations used by the orchestrator such as the file path and index
+	return !strings.Contains(fieldPath, "config.kubernetes.io/")
+}
+
+/*
+*
+discover walks the resources and proposes setter candidates for
+  - values of well-known fields such as image tags, namespaces,
+    replica counts and resource limits
+  - string values which repeat across at least MinOccurrences resources,
+    the names of the resources are only proposed for the name, label
+    and selector fields
+
+The candidates are deduplicated by value and field, e.g. the same tag of two
+images is proposed as two setters, and each candidate is only applied to the
+fields it is discovered in
+
+e.g. for the input resources
+
+	kind: Deployment
+	metadata:
+	  name: nginx
+	  namespace: dev
+	spec:
+	  replicas: 3
+	---
+	kind: Service
+	metadata:
+	  name: nginx
+	  namespace: dev
+
+the candidates are [[name: namespace, value: dev], [name: name, value: nginx],
+[name: nginx-replicas, value: 3]]
+*/
+func (cs *CreateSetters) discover(nodes []*yaml.RNode) error {
+	minOccurrences := cs.Auto.MinOccurrences
+	if minOccurrences <= 0 {
+		minOccurrences = defaultMinOccurrences
+	}
+
+	var wellKnown []discovered
+	// repeated holds the locations of each value
+	repeated := map[string][]string{}
+	// resourceNames holds the names of the resources
+	resourceNames := map[string]bool{}
+	for i := range nodes {
+		if isKptfile(nodes[i]) {
+			continue
+		}
+		resourceNames[nodes[i].GetName()] = true
+		dv := &discoveryVisitor{resourceName: nodes[i].GetName(), resource: i, values: map[string][]string{}}
+		if err := accept(dv, nodes[i]); err != nil {
+			return err
+		}
+		wellKnown = append(wellKnown, dv.wellKnown...)
+		for value, fieldPaths := range dv.values {
+			for _, fieldPath := range fieldPaths {
+				repeated[value] = append(repeated[value], location(i, fieldPath))
+			}
+		}
+	}
+
+	candidates := &candidateSet{names: map[string]string{}, keys: map[string]int{}, claimed: map[string]bool{}}
+	for _, d := range wellKnown {
+		candidates.add(d.Candidate, d.Reason+"/"+d.Name, d.location)
+	}
+	var values []string
+	for value := range repeated {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		var locations []string
+		for _, l := range repeated[value] {
+			if candidates.claimed[l] {
+				// the field is already proposed as a well-known field
+				continue
+			}
+			if resourceNames[value] && !nameFieldPattern.MatchString(locationFieldPath(l)) {
+				continue
+			}
+			locations = append(locations, l)
+		}
+		count := countResources(locations)
+		if count < minOccurrences {
+			continue
+		}
+		candidates.add(Candidate{
+			Name:   nameFromFieldPath(locationFieldPath(locations[0])),
+			Value:  value,
+			Reason: "repeated value",
+			Count:  count,
+		}, "repeated", locations...)
+	}
+
+	cs.Candidates = candidates.list
+	cs.candidateLocations = map[string][]string{}
+	for i, c := range candidates.list {
+		cs.candidateLocations[c.Name] = candidates.locations[i]
+		first := candidates.locations[i][0]
+		resource, err := strconv.Atoi(first[:strings.Index(first, ":")])
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		filePath, _, err := kioutil.GetFileAnnotations(nodes[resource])
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		cs.Candidates[i].FieldPath = locationFieldPath(first)
+		cs.Candidates[i].FilePath = filePath
+	}
+	sort.Slice(cs.Candidates, func(i, j int) bool {
+		return cs.Candidates[i].Name < cs.Candidates[j].Name
+	})
+	return nil
+}
+
+// applyCandidates adds the candidates which are not already provided
+// by the user to the ScalarSetters, restricted to the fields they are
+// discovered in
+func (cs *CreateSetters) applyCandidates() {
+	cs.locations = map[string]map[string]bool{}
+	existing := map[string]bool{}
+	for _, setter := range cs.ScalarSetters {
+		existing[setter.Name] = true
+		existing[setter.Value] = true
+	}
+	for _, c := range cs.Candidates {
+		if existing[c.Name] || existing[c.Value] {
+			continue
+		}
+		cs.ScalarSetters = append(cs.ScalarSetters, ScalarSetter{Name: c.Name, Value: c.Value})
+		cs.locations[c.Name] = map[string]bool{}
+		for _, l := range cs.candidateLocations[c.Name] {
+			cs.locations[c.Name][l] = true
+		}
+	}
+	sort.Sort(CompareSetters(cs.ScalarSetters))
+}
+
+// candidateSet deduplicates the candidates and keeps the setter names unique
+type candidateSet struct {
+	list []Candidate
+
+	// names maps the setter names to their values
+	names map[string]string
+
+	// keys maps the keys and values of the candidates to their index in list
+	keys map[string]int
+
+	// locations holds the locations of the fields of each candidate in list
+	locations [][]string
+
+	// claimed holds the locations of the fields proposed by any candidate
+	claimed map[string]bool
+}
+
+// add adds the candidate found in the locations, or increments the count
+// if a candidate with the same key and value is already present. The key
+// identifies the field, e.g. the image of a tag. If the name is taken by
+// another candidate, a numeric suffix is appended to the name
+func (s *candidateSet) add(c Candidate, key string, locations ...string) {
+	for _, l := range locations {
+		s.claimed[l] = true
+	}
+	key += "\x00" + c.Value
+	if i, found := s.keys[key]; found {
+		s.list[i].Count += c.Count
+		s.locations[i] = append(s.locations[i], locations...)
+		return
+	}
+	name := c.Name
+	for i := 2; ; i++ {
+		if _, taken := s.names[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", c.Name, i)
+	}
+	c.Name = name
+	s.names[name] = c.Value
+	s.list = append(s.list, c)
+	s.locations = append(s.locations, locations)
+	s.keys[key] = len(s.list) - 1
+}
+
+// splitImageTag splits the image into repository and tag,
+// the tag is empty for untagged images and images with digests
+func splitImageTag(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	i := strings.LastIndex(image, ":")
+	// a colon before the last slash is the registry port
+	if i < 0 || i < strings.LastIndex(image, "/") {
+		return image, ""
+	}
+	return image[:i], image[i+1:]
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// nameFromFieldPath suggests a setter name using the last field in the path
+// e.g. spec.selector.matchLabels.app is suggested as app
+func nameFromFieldPath(fieldPath string) string {
+	name := fieldPath[strings.LastIndex(fieldPath, ".")+1:]
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.Trim(nonNameChars.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		return "value"
+	}
+	return name
+}
+
+// isKptfile checks if the node is the Kptfile of the package
+func isKptfile(node *yaml.RNode) bool {
+	path, _, _ := kioutil.GetFileAnnotations(node)
+	return node.GetKind() == "Kptfile" || strings.HasSuffix(path, "Kptfile")
+}
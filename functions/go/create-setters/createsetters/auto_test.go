@@ -0,0 +1,293 @@
+package createsetters
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestDiscoverCandidates(t *testing.T) {
+	var tests = []struct {
+		name               string
+		config             string
+		input              string
+		expectedCandidates []Candidate
+		expectedResources  string
+		errMsg             string
+	}{
+		{
+			name: "propose well-known fields and repeated values",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+auto:
+  enabled: true
+`,
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: dev
+  labels:
+    app: guestbook
+  annotations:
+    config.kubernetes.io/path: frontend.yaml
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: php
+          image: gcr.io/google-samples/gb-frontend:v4
+          resources:
+            limits:
+              cpu: 500m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  namespace: dev
+  labels:
+    app: guestbook
+  annotations:
+    config.kubernetes.io/path: service.yaml
+`,
+			expectedCandidates: []Candidate{
+				{Name: "app", Value: "guestbook", Reason: "repeated value", Count: 2, FieldPath: "metadata.labels.app", FilePath: "frontend.yaml"},
+				{Name: "frontend-cpu-limit", Value: "500m", Reason: "resource limit", Count: 1, FieldPath: "spec.template.spec.containers[0].resources.limits.cpu", FilePath: "frontend.yaml"},
+				{Name: "frontend-replicas", Value: "3", Reason: "replica count", Count: 1, FieldPath: "spec.replicas", FilePath: "frontend.yaml"},
+				{Name: "gb-frontend-tag", Value: "v4", Reason: "image tag", Count: 1, FieldPath: "spec.template.spec.containers[0].image", FilePath: "frontend.yaml"},
+				{Name: "name", Value: "frontend", Reason: "repeated value", Count: 2, FieldPath: "metadata.name", FilePath: "frontend.yaml"},
+				{Name: "namespace", Value: "dev", Reason: "namespace", Count: 2, FieldPath: "metadata.namespace", FilePath: "frontend.yaml"},
+			},
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: dev
+  labels:
+    app: guestbook
+  annotations:
+    config.kubernetes.io/path: frontend.yaml
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: php
+          image: gcr.io/google-samples/gb-frontend:v4
+          resources:
+            limits:
+              cpu: 500m
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  namespace: dev
+  labels:
+    app: guestbook
+  annotations:
+    config.kubernetes.io/path: service.yaml
+`,
+		},
+		{
+			name: "apply candidates",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+auto:
+  enabled: true
+  apply: true
+`,
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: staging
+spec:
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.16.1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: staging
+`,
+			expectedCandidates: []Candidate{
+				{Name: "name", Value: "nginx", Reason: "repeated value", Count: 2, FieldPath: "metadata.name"},
+				{Name: "namespace", Value: "staging", Reason: "namespace", Count: 2, FieldPath: "metadata.namespace"},
+				{Name: "nginx-tag", Value: "1.16.1", Reason: "image tag", Count: 1, FieldPath: "spec.template.spec.containers[0].image"},
+			},
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx # kpt-set: ${name}
+  namespace: staging # kpt-set: ${namespace}
+spec:
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.16.1 # kpt-set: nginx:${nginx-tag}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx # kpt-set: ${name}
+  namespace: staging # kpt-set: ${namespace}
+`,
+		},
+		{
+			name: "apply candidates only to the fields they are discovered in",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+auto:
+  enabled: true
+  apply: true
+`,
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.2
+          args:
+            - "--v=3"
+            - "--timeout=30s"
+          ports:
+            - containerPort: 8443
+        - name: cache
+          image: redis:1.2
+`,
+			expectedCandidates: []Candidate{
+				{Name: "nginx-tag", Value: "1.2", Reason: "image tag", Count: 1, FieldPath: "spec.template.spec.containers[0].image"},
+				{Name: "redis-tag", Value: "1.2", Reason: "image tag", Count: 1, FieldPath: "spec.template.spec.containers[1].image"},
+				{Name: "web-replicas", Value: "3", Reason: "replica count", Count: 1, FieldPath: "spec.replicas"},
+			},
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3 # kpt-set: ${web-replicas}
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.2 # kpt-set: nginx:${nginx-tag}
+          args:
+            - "--v=3"
+            - "--timeout=30s"
+          ports:
+            - containerPort: 8443
+        - name: cache
+          image: redis:1.2 # kpt-set: redis:${redis-tag}
+`,
+		},
+		{
+			name: "skip parameterized fields and respect min occurrences",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+auto:
+  enabled: true
+  minOccurrences: 3
+`,
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: dev # kpt-set: ${ns}
+data:
+  color: blue
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+data:
+  color: blue
+`,
+			expectedResources: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: dev # kpt-set: ${ns}
+data:
+  color: blue
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+data:
+  color: blue
+`,
+		},
+		{
+			name: "typed config without setters",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+`,
+			errMsg: "CreateSetters must have at least one setter or enable auto discovery",
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			s := &CreateSetters{}
+			err := Decode(kyaml.MustParse(test.config), s)
+			if test.errMsg != "" {
+				if !assert.EqualError(t, err, test.errMsg) {
+					t.FailNow()
+				}
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			var out bytes.Buffer
+			err = kio.Pipeline{
+				Inputs:  []kio.Reader{&kio.ByteReader{Reader: strings.NewReader(test.input), OmitReaderAnnotations: true}},
+				Filters: []kio.Filter{s},
+				Outputs: []kio.Writer{&kio.ByteWriter{Writer: &out}},
+			}.Execute()
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			if !assert.Equal(t, test.expectedCandidates, s.Candidates) {
+				t.FailNow()
+			}
+			if !assert.Equal(t, test.expectedResources, out.String()) {
+				t.FailNow()
+			}
+		})
+	}
+}
@@ -13,6 +13,11 @@ import (
 
 var _ kio.Filter = &CreateSetters{}
 
+const (
+	fnConfigKind       = "CreateSetters"
+	fnConfigAPIVersion = "fn.kpt.dev/v1alpha1"
+)
+
 // CreateSetters creates a comment for the resource fields which
 // contain the same value as setter value
 type CreateSetters struct {
@@ -26,29 +31,75 @@ type CreateSetters struct {
 	// ArraySetters holds the user provided values for array setters
 	ArraySetters []ArraySetter
 
+	// Auto configures the automatic discovery of setters
+	Auto AutoSetters
+
+	// Candidates are the setters proposed by the automatic discovery
+	Candidates []Candidate
+
+	// candidateLocations maps the names of the Candidates to the
+	// locations of the fields they are discovered in
+	candidateLocations map[string][]string
+
+	// locations maps the names of the applied candidates to the
+	// locations of the fields they are restricted to
+	locations map[string]map[string]bool
+
 	// Results are the results of adding setter comments
 	Results []*Result
 
 	// filePath file path of resource
 	filePath string
+
+	// kind is the kind of resource
+	kind string
+
+	// resource is the index of the resource
+	resource int
+
+	// scoped is true if any of the scalar setters is restricted
+	// to a scope or matches only whole words
+	scoped bool
 }
 
 // ScalarSetter stores name and value of the map setter
 type ScalarSetter struct {
 	// Name is the name of the setter
-	Name string
+	Name string `yaml:"name"`
 
 	// Value is the value of the field to which setter comment is added.
-	Value string
+	Value string `yaml:"value"`
+
+	// Match is the matching mode of the setter value, either
+	// MatchSubstring(default) or MatchWord
+	Match string `yaml:"match,omitempty"`
+
+	// Scope restricts the fields to which setter comment is added.
+	Scope `yaml:",inline"`
 }
 
 // ArraySetter stores name and values of the array setter
 type ArraySetter struct {
 	// Name is the name of the setter
-	Name string
+	Name string `yaml:"name"`
 
 	// Values are the values of the field to which setter comment is added.
-	Values []string
+	Values []string `yaml:"values"`
+
+	// Scope restricts the fields to which setter comment is added.
+	Scope `yaml:",inline"`
+}
+
+// Config is the typed functionConfig of create-setters
+type Config struct {
+	// ScalarSetters holds the name and value of the scalar setters
+	ScalarSetters []ScalarSetter `yaml:"scalarSetters,omitempty"`
+
+	// ArraySetters holds the name and values of the array setters
+	ArraySetters []ArraySetter `yaml:"arraySetters,omitempty"`
+
+	// Auto configures the automatic discovery of setters
+	Auto AutoSetters `yaml:"auto,omitempty"`
 }
 
 // Result holds result of create-setters operation
@@ -84,6 +135,18 @@ func (a CompareSetters) Swap(i, j int) {
 
 // Filter implements CreatSetters as a yaml.Filter
 func (cs *CreateSetters) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	if cs.Auto.Enabled {
+		if err := cs.discover(nodes); err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if cs.Auto.Apply {
+			cs.applyCandidates()
+		}
+	}
+	if len(cs.ScalarSetters) == 0 && len(cs.ArraySetters) == 0 {
+		// only the setter candidates are reported
+		return nodes, nil
+	}
 	cs.preProcessScalarSetters()
 	for i := range nodes {
 		filePath, _, err := kioutil.GetFileAnnotations(nodes[i])
@@ -91,6 +154,8 @@ func (cs *CreateSetters) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			return nodes, err
 		}
 		cs.filePath = filePath
+		cs.kind = nodes[i].GetKind()
+		cs.resource = i
 		err = accept(cs, nodes[i])
 		if err != nil {
 			return nil, errors.Wrap(err)
@@ -110,7 +175,11 @@ Using strings.NewReplacer and the args, creates *strings.Replacer
 func (cs *CreateSetters) preProcessScalarSetters() {
 	// replacerArgs contains the setter values with parameter as pairs
 	var replacerArgs []string
+	cs.scoped = len(cs.locations) > 0
 	for _, setter := range cs.ScalarSetters {
+		if !setter.Scope.isEmpty() || setter.Match == MatchWord {
+			cs.scoped = true
+		}
 		replacerArgs = append(replacerArgs, setter.Value)
 		replacerArgs = append(replacerArgs, fmt.Sprintf("${%s}", setter.Name))
 	}
@@ -181,13 +250,21 @@ func (cs *CreateSetters) visitMapping(object *yaml.RNode, path string) error {
 		for _, values := range elements {
 			nodeValues = append(nodeValues, values.YNode().Value)
 		}
+		// scalarSetters are the ScalarSetters which apply to any of the values
+		scalarSetters := cs.ScalarSetters
+		if cs.scoped {
+			scalarSetters = nil
+			for i := range elements {
+				scalarSetters = append(scalarSetters, cs.scalarSettersFor(fmt.Sprintf("%s[%d]", fieldPath, i))...)
+			}
+		}
 		sort.Strings(nodeValues)
 
 		// checks if any of the values of node matches with ScalarSetters
 		// changes the node to FoldedStyle
 		nodeToAddComment := node.Value
 		if nodeToAddComment.YNode().Style == yaml.FlowStyle {
-			if hasMatchValue(nodeValues, cs.ScalarSetters) {
+			if hasMatchValue(nodeValues, scalarSetters) {
 				// changes the node style to FoldedStyle
 				nodeToAddComment.YNode().Style = yaml.FoldedStyle
 				// adds the comment to the key for the FoldedStyle value node
@@ -199,6 +276,9 @@ func (cs *CreateSetters) visitMapping(object *yaml.RNode, path string) error {
 		}
 
 		for _, arraySetters := range cs.ArraySetters {
+			if !arraySetters.Scope.inScope(cs.kind, fieldPath) {
+				continue
+			}
 			// checks if all the values in node are present in array setter
 			if checkEqual(nodeValues, arraySetters.Values) {
 				if nodeToAddComment.YNode().Style == yaml.FlowStyle && len(nodeValues) > 0 {
@@ -253,7 +333,13 @@ func (cs *CreateSetters) visitScalar(object *yaml.RNode, path string) error {
 		return nil
 	}
 
-	linecomment, valueMatch := getLineComment(object.YNode().Value, cs.replacer)
+	var linecomment string
+	var valueMatch bool
+	if cs.scoped {
+		linecomment, valueMatch = replaceSetters(object.YNode().Value, cs.scalarSettersFor(path))
+	} else {
+		linecomment, valueMatch = getLineComment(object.YNode().Value, cs.replacer)
+	}
 
 	// sets the linecomment if the match is found
 	if valueMatch {
@@ -309,7 +395,7 @@ func hasMultipleLines(value string) bool {
 func hasMatchValue(nodeValues []string, setters []ScalarSetter) bool {
 	for _, value := range nodeValues {
 		for _, setter := range setters {
-			if strings.Contains(value, setter.Value) {
+			if setterMatches(value, setter) {
 				return true
 			}
 		}
@@ -368,6 +454,9 @@ ScalarSetters array is transformed to
 	[[name: ubuntu, value: nginx-abc], [name: image, value: nginx]]
 */
 func Decode(rn *yaml.RNode, fcd *CreateSetters) error {
+	if rn.GetKind() == fnConfigKind {
+		return decodeConfig(rn, fcd)
+	}
 	if len(rn.GetDataMap()) == 0 {
 		return fmt.Errorf("config map cannot be empty")
 	}
@@ -391,3 +480,42 @@ func Decode(rn *yaml.RNode, fcd *CreateSetters) error {
 	sort.Sort(CompareSetters(fcd.ScalarSetters))
 	return nil
 }
+
+// decodeConfig decodes the typed CreateSetters functionConfig into CreateSetters struct
+func decodeConfig(rn *yaml.RNode, fcd *CreateSetters) error {
+	if rn.GetApiVersion() != fnConfigAPIVersion {
+		return fmt.Errorf("unsupported apiVersion %q for %s, expected %q",
+			rn.GetApiVersion(), fnConfigKind, fnConfigAPIVersion)
+	}
+	var config Config
+	if err := yaml.Unmarshal([]byte(rn.MustString()), &config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fnConfigKind, err)
+	}
+	if len(config.ScalarSetters) == 0 && len(config.ArraySetters) == 0 && !config.Auto.Enabled {
+		return fmt.Errorf("%s must have at least one setter or enable auto discovery", fnConfigKind)
+	}
+	for _, setter := range config.ScalarSetters {
+		if setter.Name == "" || setter.Value == "" {
+			return fmt.Errorf("scalar setters must have a name and a value")
+		}
+		if setter.Match != "" && setter.Match != MatchSubstring && setter.Match != MatchWord {
+			return fmt.Errorf("invalid match %q for setter %q, must be one of [%s, %s]",
+				setter.Match, setter.Name, MatchSubstring, MatchWord)
+		}
+		fcd.ScalarSetters = append(fcd.ScalarSetters, setter)
+	}
+	for _, setter := range config.ArraySetters {
+		if setter.Name == "" {
+			return fmt.Errorf("array setters must have a name")
+		}
+		values := append([]string{}, setter.Values...)
+		sort.Strings(values)
+		fcd.ArraySetters = append(fcd.ArraySetters, ArraySetter{Name: setter.Name, Values: values, Scope: setter.Scope})
+	}
+	fcd.Auto = config.Auto
+
+	// sorts all the Scalar Setters in lexicographically
+	// decreasing order of it's Value
+	sort.Sort(CompareSetters(fcd.ScalarSetters))
+	return nil
+}
@@ -520,6 +520,147 @@ spec:
   image: dev # kpt-set: ${role}
 `,
 		},
+		{
+			name: "word boundary match",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+scalarSetters:
+  - name: env
+    value: dev
+    match: word
+`,
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-dev-config
+data:
+  env: dev
+  device: /dev/sda
+  team: devops
+`,
+			expectedResources: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-dev-config # kpt-set: my-${env}-config
+data:
+  env: dev # kpt-set: ${env}
+  device: /dev/sda # kpt-set: /${env}/sda
+  team: devops
+`,
+		},
+		{
+			name: "field path and kind scopes",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+scalarSetters:
+  - name: env
+    value: dev
+    fieldPaths:
+      - metadata.labels.*
+      - data.env
+  - name: app
+    value: nginx
+    kinds:
+      - Deployment
+arraySetters:
+  - name: hosts
+    values: [foo, bar]
+    fieldPaths:
+      - spec.**.hosts
+`,
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  labels:
+    env: dev
+spec:
+  devices: dev
+  hosts: [foo, bar]
+  other: [foo, bar]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx
+data:
+  env: dev
+`,
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx # kpt-set: ${app}
+  labels:
+    env: dev # kpt-set: ${env}
+spec:
+  devices: dev
+  hosts: # kpt-set: ${hosts}
+    - foo
+    - bar
+  other: [foo, bar]
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx
+data:
+  env: dev # kpt-set: ${env}
+`,
+		},
+		{
+			name: "list elements match the field path of the list",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+scalarSetters:
+  - name: verbosity
+    value: "3"
+    fieldPaths:
+      - spec.args
+`,
+			input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: app-3
+spec:
+  args:
+    - "--v=3"
+    - "--log=stderr"
+  replicas: 3
+`,
+			expectedResources: `apiVersion: v1
+kind: Pod
+metadata:
+  name: app-3
+spec:
+  args:
+    - "--v=3" # kpt-set: --v=${verbosity}
+    - "--log=stderr"
+  replicas: 3
+`,
+		},
+		{
+			name: "invalid match mode",
+			config: `
+apiVersion: fn.kpt.dev/v1alpha1
+kind: CreateSetters
+metadata:
+  name: create-setters-fn-config
+scalarSetters:
+  - name: env
+    value: dev
+    match: regex
+`,
+			errMsg: `invalid match "regex" for setter "env", must be one of [substring, word]`,
+		},
 	}
 	for i := range tests {
 		test := tests[i]
@@ -0,0 +1,144 @@
+package createsetters
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kptdev/krm-functions-catalog/functions/go/internal/pathmatch"
+)
+
+const (
+	// MatchSubstring adds the setter comment if the setter value is
+	// a substring of the field value, this is the default
+	MatchSubstring = "substring"
+
+	// MatchWord adds the setter comment only if the setter value
+	// matches whole words of the field value
+	MatchWord = "word"
+)
+
+// Scope restricts the fields to which the setter comment is added
+type Scope struct {
+	// FieldPaths are the path expressions of the fields to which the
+	// setter comment is added, e.g. spec.template.**.image
+	FieldPaths []string `yaml:"fieldPaths,omitempty"`
+
+	// Kinds are the kinds of the resources to which the setter comment is added
+	Kinds []string `yaml:"kinds,omitempty"`
+}
+
+// isEmpty checks if the scope doesn't restrict any field
+func (s Scope) isEmpty() bool {
+	return len(s.FieldPaths) == 0 && len(s.Kinds) == 0
+}
+
+// inScope checks if the field of the resource of input kind is in the scope
+func (s Scope) inScope(kind, fieldPath string) bool {
+	if len(s.Kinds) > 0 && !contains(s.Kinds, kind) {
+		return false
+	}
+	if len(s.FieldPaths) == 0 {
+		return true
+	}
+	for _, pattern := range s.FieldPaths {
+		if pathMatch(fieldPath, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// scalarSettersFor returns the scalar setters which apply to the field
+func (cs *CreateSetters) scalarSettersFor(fieldPath string) []ScalarSetter {
+	var setters []ScalarSetter
+	for _, setter := range cs.ScalarSetters {
+		if locations, found := cs.locations[setter.Name]; found && !locations[location(cs.resource, fieldPath)] {
+			continue
+		}
+		if setter.Scope.inScope(cs.kind, fieldPath) {
+			setters = append(setters, setter)
+		}
+	}
+	return setters
+}
+
+/*
+*
+replaceSetters replaces the parts of the node value which match the setter values
+with the ${setterName}, setters are tried in order at each position of the value
+as done by *strings.Replacer, and the setters with MatchWord mode only match at
+word boundaries
+e.g. for input ScalarSetters [[name: env, value: dev, match: word]]
+
+	device      -> no match
+	my-dev-ns   -> my-${env}-ns
+*/
+func replaceSetters(nodeValue string, setters []ScalarSetter) (string, bool) {
+	var output strings.Builder
+	valueMatch := false
+	for i := 0; i < len(nodeValue); {
+		matched := false
+		for _, setter := range setters {
+			if setter.Value == "" || !strings.HasPrefix(nodeValue[i:], setter.Value) {
+				continue
+			}
+			if setter.Match == MatchWord && !isWordBoundary(nodeValue, i, i+len(setter.Value)) {
+				continue
+			}
+			output.WriteString("${" + setter.Name + "}")
+			i += len(setter.Value)
+			matched, valueMatch = true, true
+			break
+		}
+		if !matched {
+			output.WriteByte(nodeValue[i])
+			i++
+		}
+	}
+	return output.String(), valueMatch
+}
+
+// setterMatches checks if the setter value matches the node value
+func setterMatches(nodeValue string, setter ScalarSetter) bool {
+	_, match := replaceSetters(nodeValue, []ScalarSetter{setter})
+	return match
+}
+
+// isWordBoundary checks if the substring value[start:end] is not
+// surrounded by letters, digits or underscores
+func isWordBoundary(value string, start, end int) bool {
+	if start > 0 && isWordChar(rune(value[start-1])) {
+		return false
+	}
+	if end < len(value) && isWordChar(rune(value[end])) {
+		return false
+	}
+	return true
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// pathMatch checks if the field path matches the path expression, the elements
+// of a list also match the path of the list e.g. spec.args matches spec.args[0]
+func pathMatch(fieldPath, pattern string) bool {
+	for {
+		if pathmatch.Match(fieldPath, pattern) {
+			return true
+		}
+		if !strings.HasSuffix(fieldPath, "]") {
+			return false
+		}
+		fieldPath = fieldPath[:strings.LastIndex(fieldPath, "[")]
+	}
+}
@@ -27,6 +27,50 @@ Here, the key is the name of the setter, and value is the field value to be para
 
 >? If this function adds setter comments to the fields for which you didn't intend to parameterize,
 you can simply review and delete/modify those comments manually.
+
+Alternatively, the setters can be provided using the ` + "`" + `CreateSetters` + "`" + ` custom resource.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: CreateSetters
+  metadata:
+    name: create-setters-fn-config
+  scalarSetters:
+    - name: setter_name1
+      value: setter_value1
+      match: word
+      fieldPaths:
+        - metadata.labels.*
+      kinds:
+        - Deployment
+  arraySetters:
+    - name: setter_name2
+      values:
+        - value1
+        - value2
+      fieldPaths:
+        - spec.**.hosts
+  auto:
+    enabled: true
+    apply: false
+    minOccurrences: 2
+
+Each setter can be restricted to the fields matching any of the ` + "`" + `fieldPaths` + "`" + ` and to the
+resources of any of the ` + "`" + `kinds` + "`" + `. The path expressions follow the ` + "`" + `by-path` + "`" + ` syntax of
+the search-replace function, ` + "`" + `*` + "`" + ` matches any field, ` + "`" + `**` + "`" + ` matches zero or more fields and
+` + "`" + `a[*]` + "`" + ` matches any element of the array ` + "`" + `a` + "`" + `. A path also matches the elements of the list at
+the path, e.g. ` + "`" + `spec.args` + "`" + ` matches ` + "`" + `spec.args[0]` + "`" + `. By default, a scalar setter matches any
+substring of the field value. With ` + "`" + `match: word` + "`" + `, the setter value only matches whole words,
+e.g. ` + "`" + `dev` + "`" + ` matches ` + "`" + `my-dev-ns` + "`" + ` but not ` + "`" + `device` + "`" + ` or ` + "`" + `devops` + "`" + `.
+
+When ` + "`" + `auto.enabled` + "`" + ` is ` + "`" + `true` + "`" + `, the function scans the package and proposes setter candidates
+for the values of well-known fields (image tags, namespaces, replica counts and resource limits)
+and for the string values which repeat across at least ` + "`" + `auto.minOccurrences` + "`" + ` resources (defaults to 2).
+The names of the resources are only proposed for the ` + "`" + `metadata.name` + "`" + `, label and selector fields.
+The candidates are reported as results with a suggested setter name and the field path and file of
+the first field they are found in, a value found in different fields, e.g. the same tag of two images, is proposed as different setters. Fields which already have
+a setter comment are skipped. When ` + "`" + `auto.apply` + "`" + ` is ` + "`" + `true` + "`" + `, the setter comments are also added for the
+candidates which are not already provided in ` + "`" + `scalarSetters` + "`" + `, only to the fields the candidates are
+discovered in.
 `
 var CreateSettersExamples = `
 ### Setting comments for scalar nodes
@@ -3,6 +3,7 @@ module github.com/kptdev/krm-functions-catalog/functions/go/create-setters
 go 1.24.10
 
 require (
+	github.com/kptdev/krm-functions-catalog/functions/go/internal v0.0.0
 	github.com/stretchr/testify v1.6.1
 	sigs.k8s.io/kustomize/kyaml v0.10.21
 )
@@ -28,3 +29,5 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	k8s.io/kube-openapi v0.0.0-20210421082810-95288971da7e // indirect
 )
+
+replace github.com/kptdev/krm-functions-catalog/functions/go/internal => ../internal
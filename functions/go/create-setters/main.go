@@ -68,7 +68,21 @@ func getSetters(fc *kyaml.RNode) (createsetters.CreateSetters, error) {
 // equivalent items([]framework.Item)
 func resultsToItems(sr createsetters.CreateSetters) ([]framework.ResultItem, error) {
 	var items []framework.ResultItem
+	for _, c := range sr.Candidates {
+		items = append(items, framework.ResultItem{
+			Message:  fmt.Sprintf("Proposed setter %q for %s %q found %d time(s)", c.Name, c.Reason, c.Value, c.Count),
+			Severity: framework.Info,
+			Field:    framework.Field{Path: c.FieldPath},
+			File:     framework.File{Path: c.FilePath},
+		})
+	}
 	if len(sr.Results) == 0 {
+		if sr.Auto.Enabled {
+			if len(items) == 0 {
+				return nil, fmt.Errorf("no setter candidates found in the package")
+			}
+			return items, nil
+		}
 		return nil, fmt.Errorf("no matches for the input list of setters")
 	}
 	for _, res := range sr.Results {
@@ -0,0 +1,324 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	mutationsunversioned "github.com/open-policy-agent/gatekeeper/apis/mutations/unversioned"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/match"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/mutators/core"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/path/parser"
+	patht "github.com/open-policy-agent/gatekeeper/pkg/mutation/path/tester"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/schema"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtimeschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The Gatekeeper version of the function doesn't provide the AssignImage mutator,
+// which is implemented here with the same semantics on top of the mutation core.
+
+var (
+	// imageDomainRegexp matches a registry host with an optional port, e.g. registry.example.com:5000
+	imageDomainRegexp = regexp.MustCompile(`^(localhost|[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*)(:[0-9]+)?$`)
+	// imagePathRegexp matches the repository path of an image, e.g. library/nginx
+	imagePathRegexp = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+	// imageTagRegexp matches a tag or a digest with its separator, e.g. :latest or @sha256:...
+	imageTagRegexp = regexp.MustCompile(`^(:[\w][\w.-]{0,127}|@[A-Za-z][A-Za-z0-9]*([-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})$`)
+)
+
+// stringType is the terminal type of the location of an AssignImage mutation
+const stringType = parser.NodeType("String")
+
+// assignImage is the AssignImage mutation object
+type assignImage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec assignImageSpec `json:"spec,omitempty"`
+}
+
+type assignImageSpec struct {
+	ApplyTo    []match.ApplyTo       `json:"applyTo,omitempty"`
+	Match      match.Match           `json:"match,omitempty"`
+	Location   string                `json:"location,omitempty"`
+	Parameters assignImageParameters `json:"parameters,omitempty"`
+}
+
+type assignImageParameters struct {
+	PathTests []mutationsunversioned.PathTest `json:"pathTests,omitempty"`
+
+	// AssignDomain sets the domain component of the image, e.g. registry.example.com
+	AssignDomain string `json:"assignDomain,omitempty"`
+	// AssignPath sets the path component of the image, e.g. library/nginx
+	AssignPath string `json:"assignPath,omitempty"`
+	// AssignTag sets the tag or the digest of the image, e.g. :latest
+	AssignTag string `json:"assignTag,omitempty"`
+}
+
+// assignImageMutator is the mutator of an AssignImage mutation
+type assignImageMutator struct {
+	id          types.ID
+	assignImage *assignImage
+
+	path     parser.Path
+	bindings []runtimeschema.GroupVersionKind
+	tester   *patht.Tester
+}
+
+var _ schema.MutatorWithSchema = &assignImageMutator{}
+
+// mutatorForAssignImage returns the mutator of the AssignImage mutation, like
+// mutators.MutatorForAssign it rejects the invalid mutations
+func mutatorForAssignImage(a *assignImage) (*assignImageMutator, error) {
+	path, err := parser.Parse(a.Spec.Location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid location format `%s` for AssignImage %s: %w", a.Spec.Location, a.GetName(), err)
+	}
+	if len(path.Nodes) == 0 {
+		return nil, fmt.Errorf("empty location for AssignImage %s", a.GetName())
+	}
+	if obj, ok := path.Nodes[0].(*parser.Object); ok && obj.Reference == "metadata" {
+		return nil, fmt.Errorf("assignImage %s can't change metadata", a.GetName())
+	}
+	last := path.Nodes[len(path.Nodes)-1]
+	if last.Type() == parser.ListNode {
+		return nil, fmt.Errorf("assignImage %s can't mutate a list-type field", a.GetName())
+	}
+	if len(path.Nodes) > 1 {
+		if list, ok := path.Nodes[len(path.Nodes)-2].(*parser.List); ok && last.(*parser.Object).Reference == list.KeyField {
+			return nil, fmt.Errorf("invalid path format: changing the item key is not allowed")
+		}
+	}
+
+	p := a.Spec.Parameters
+	if err := validateImageParts(p.AssignDomain, p.AssignPath, p.AssignTag); err != nil {
+		return nil, fmt.Errorf("assignImage %s has invalid parameters: %w", a.GetName(), err)
+	}
+
+	var pathTests []patht.Test
+	for _, pt := range p.PathTests {
+		sub, err := parser.Parse(pt.SubPath)
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing sub path `%s` for AssignImage %s: %w", pt.SubPath, a.GetName(), err)
+		}
+		pathTests = append(pathTests, patht.Test{SubPath: sub, Condition: pt.Condition})
+	}
+	tester, err := patht.New(path, pathTests)
+	if err != nil {
+		return nil, err
+	}
+
+	gvkSet := map[runtimeschema.GroupVersionKind]bool{}
+	for _, applyTo := range a.Spec.ApplyTo {
+		if len(applyTo.Groups) == 0 || len(applyTo.Versions) == 0 || len(applyTo.Kinds) == 0 {
+			return nil, fmt.Errorf("invalid applyTo for AssignImage mutator %s, all of group, version and kind must be specified", a.GetName())
+		}
+		for _, gvk := range applyTo.Flatten() {
+			gvkSet[gvk] = true
+		}
+	}
+	if len(gvkSet) == 0 {
+		return nil, fmt.Errorf("applyTo required for AssignImage mutator %s", a.GetName())
+	}
+	var gvks []runtimeschema.GroupVersionKind
+	for gvk := range gvkSet {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+
+	return &assignImageMutator{
+		id:          types.ID{Group: mutationsGroup, Kind: "AssignImage", Namespace: a.GetNamespace(), Name: a.GetName()},
+		assignImage: a.DeepCopy(),
+		path:        path,
+		bindings:    gvks,
+		tester:      tester,
+	}, nil
+}
+
+// validateImageParts checks that at least one component of the image is assigned
+// and that the components are valid
+func validateImageParts(domain, path, tag string) error {
+	if domain == "" && path == "" && tag == "" {
+		return errors.New("at least one of assignDomain, assignPath or assignTag must be set")
+	}
+	if domain != "" {
+		if !imageDomainRegexp.MatchString(domain) {
+			return fmt.Errorf("assignDomain %q is not a valid domain", domain)
+		}
+		if !strings.ContainsAny(domain, ".:") && domain != "localhost" {
+			return fmt.Errorf("assignDomain %q must contain a . or a : or be localhost", domain)
+		}
+	}
+	if path != "" {
+		if !imagePathRegexp.MatchString(path) {
+			return fmt.Errorf("assignPath %q is not a valid path", path)
+		}
+		// the first component of the path would be read as the domain
+		if d, _ := splitImageDomain(path); d != "" && domain == "" {
+			return fmt.Errorf("assignPath %q starts with a domain, the domain must be set with assignDomain", path)
+		}
+	}
+	if tag != "" && !imageTagRegexp.MatchString(tag) {
+		return fmt.Errorf("assignTag %q must be a tag starting with : or a digest starting with @", tag)
+	}
+	return nil
+}
+
+func (m *assignImageMutator) Matches(obj client.Object, ns *corev1.Namespace) bool {
+	if !match.AppliesTo(m.assignImage.Spec.ApplyTo, obj) {
+		return false
+	}
+	matches, err := match.Matches(&m.assignImage.Spec.Match, obj, ns)
+	return err == nil && matches
+}
+
+func (m *assignImageMutator) Mutate(obj *unstructured.Unstructured) (bool, error) {
+	p := m.assignImage.Spec.Parameters
+	return core.Mutate(m.path, m.tester, &imageSetter{domain: p.AssignDomain, path: p.AssignPath, tag: p.AssignTag}, obj)
+}
+
+func (m *assignImageMutator) ID() types.ID {
+	return m.id
+}
+
+func (m *assignImageMutator) HasDiff(mutator types.Mutator) bool {
+	other, ok := mutator.(*assignImageMutator)
+	if !ok {
+		return true
+	}
+	return other.id != m.id ||
+		!reflect.DeepEqual(other.path, m.path) ||
+		!reflect.DeepEqual(other.bindings, m.bindings) ||
+		!reflect.DeepEqual(other.assignImage.Spec, m.assignImage.Spec)
+}
+
+func (m *assignImageMutator) DeepCopy() types.Mutator {
+	res := &assignImageMutator{
+		id:          m.id,
+		assignImage: m.assignImage.DeepCopy(),
+		path:        parser.Path{Nodes: make([]parser.Node, len(m.path.Nodes))},
+		bindings:    make([]runtimeschema.GroupVersionKind, len(m.bindings)),
+		tester:      m.tester.DeepCopy(),
+	}
+	copy(res.path.Nodes, m.path.Nodes)
+	copy(res.bindings, m.bindings)
+	return res
+}
+
+func (m *assignImageMutator) Path() parser.Path {
+	return m.path
+}
+
+func (m *assignImageMutator) String() string {
+	return fmt.Sprintf("%s/%s/%s:%d", m.id.Kind, m.id.Namespace, m.id.Name, m.assignImage.GetGeneration())
+}
+
+func (m *assignImageMutator) SchemaBindings() []runtimeschema.GroupVersionKind {
+	return m.bindings
+}
+
+func (m *assignImageMutator) TerminalType() parser.NodeType {
+	return stringType
+}
+
+// DeepCopy returns a copy of the AssignImage mutation
+func (a *assignImage) DeepCopy() *assignImage {
+	out := &assignImage{TypeMeta: a.TypeMeta}
+	a.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	for _, applyTo := range a.Spec.ApplyTo {
+		out.Spec.ApplyTo = append(out.Spec.ApplyTo, *applyTo.DeepCopy())
+	}
+	a.Spec.Match.DeepCopyInto(&out.Spec.Match)
+	out.Spec.Location = a.Spec.Location
+	out.Spec.Parameters = a.Spec.Parameters
+	out.Spec.Parameters.PathTests = append([]mutationsunversioned.PathTest(nil), a.Spec.Parameters.PathTests...)
+	return out
+}
+
+// imageSetter replaces the assigned components of the image at the location,
+// a missing image is created from the assigned components
+type imageSetter struct {
+	domain, path, tag string
+}
+
+var _ core.Setter = &imageSetter{}
+
+func (s *imageSetter) KeyedListOkay() bool { return false }
+
+func (s *imageSetter) KeyedListValue() (map[string]interface{}, error) {
+	return nil, errors.New("assignImage can't assign a keyed list element")
+}
+
+func (s *imageSetter) SetValue(obj map[string]interface{}, key string) error {
+	current := ""
+	if val, ok := obj[key]; ok {
+		if current, ok = val.(string); !ok {
+			return fmt.Errorf("expected value at AssignImage location to be a string, got %v of type %T", val, val)
+		}
+	}
+	img := parseImage(current)
+	if s.domain != "" {
+		img.domain = s.domain
+	}
+	if s.path != "" {
+		img.path = s.path
+	}
+	if s.tag != "" {
+		img.tag = s.tag
+	}
+	obj[key] = img.String()
+	return nil
+}
+
+// image is a container image reference split into its domain, path and tag or digest
+type image struct {
+	domain, path, tag string
+}
+
+func parseImage(ref string) image {
+	domain, remainder := splitImageDomain(ref)
+	img := image{domain: domain, path: remainder}
+	if i := strings.IndexAny(remainder, ":@"); i >= 0 {
+		img.path, img.tag = remainder[:i], remainder[i:]
+	}
+	return img
+}
+
+func (img image) String() string {
+	if img.domain == "" {
+		return img.path + img.tag
+	}
+	return img.domain + "/" + img.path + img.tag
+}
+
+// splitImageDomain returns the domain and the remainder of the image, the first
+// component is a domain if it contains a . or a : or is localhost like in docker
+func splitImageDomain(ref string) (string, string) {
+	i := strings.IndexRune(ref, '/')
+	if i < 0 || (!strings.ContainsAny(ref[:i], ".:") && ref[:i] != "localhost") {
+		return "", ref
+	}
+	return ref[:i], ref[i+1:]
+}
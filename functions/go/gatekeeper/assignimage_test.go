@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestImageSetter(t *testing.T) {
+	testcases := []struct {
+		name     string
+		setter   imageSetter
+		image    interface{}
+		expected string
+	}{
+		{name: "domain", setter: imageSetter{domain: "registry.example.com"}, image: "nginx", expected: "registry.example.com/nginx"},
+		{name: "replace domain", setter: imageSetter{domain: "localhost:5000"}, image: "docker.io/library/nginx:1.25", expected: "localhost:5000/library/nginx:1.25"},
+		{name: "path without domain", setter: imageSetter{path: "library/nginx"}, image: "nginx:1.25", expected: "library/nginx:1.25"},
+		{name: "tag replaces digest", setter: imageSetter{tag: ":latest"}, image: "localhost/nginx@sha256:abcd", expected: "localhost/nginx:latest"},
+		{name: "missing image", setter: imageSetter{domain: "registry.example.com", path: "nginx", tag: ":1.25"}, expected: "registry.example.com/nginx:1.25"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := map[string]interface{}{}
+			if tc.image != nil {
+				obj["image"] = tc.image
+			}
+			if err := tc.setter.SetValue(obj, "image"); err != nil {
+				t.Fatal(err)
+			}
+			if obj["image"] != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, obj["image"])
+			}
+		})
+	}
+}
+
+func TestMutatorForAssignImageErrors(t *testing.T) {
+	testcases := []struct {
+		name     string
+		location string
+		params   assignImageParameters
+		errMsg   string
+	}{
+		{name: "no parameters", location: "spec.containers[name:*].image", errMsg: "assignImage registry has invalid parameters: at least one of assignDomain, assignPath or assignTag must be set"},
+		{name: "invalid domain", location: "spec.containers[name:*].image", params: assignImageParameters{AssignDomain: "registry"}, errMsg: `assignImage registry has invalid parameters: assignDomain "registry" must contain a . or a : or be localhost`},
+		{name: "path with domain", location: "spec.containers[name:*].image", params: assignImageParameters{AssignPath: "example.com/nginx"}, errMsg: `assignImage registry has invalid parameters: assignPath "example.com/nginx" starts with a domain, the domain must be set with assignDomain`},
+		{name: "invalid tag", location: "spec.containers[name:*].image", params: assignImageParameters{AssignTag: "latest"}, errMsg: `assignImage registry has invalid parameters: assignTag "latest" must be a tag starting with : or a digest starting with @`},
+		{name: "metadata", location: "metadata.annotations.image", params: assignImageParameters{AssignTag: ":latest"}, errMsg: "assignImage registry can't change metadata"},
+		{name: "list", location: "spec.containers[name:nginx]", params: assignImageParameters{AssignTag: ":latest"}, errMsg: "assignImage registry can't mutate a list-type field"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &assignImage{Spec: assignImageSpec{Location: tc.location, Parameters: tc.params}}
+			a.SetName("registry")
+			_, err := mutatorForAssignImage(a)
+			if err == nil || err.Error() != tc.errMsg {
+				t.Errorf("expected error %q, got %v", tc.errMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,416 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/match"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	templatesGroup   = "templates.gatekeeper.sh"
+	constraintsGroup = "constraints.gatekeeper.sh"
+
+	// celEngine is the engine of the ConstraintTemplate code written in CEL
+	celEngine = "K8sNativeValidation"
+)
+
+// celValidation is a CEL expression which must evaluate to true for the
+// object to be valid
+type celValidation struct {
+	Expression        string `json:"expression"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+}
+
+// celNamedExpression is a variable or a match condition
+type celNamedExpression struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// celSource holds the CEL expressions of a K8sNativeValidation ConstraintTemplate
+// or of a ValidatingAdmissionPolicy, which share the same fields
+type celSource struct {
+	Validations     []celValidation      `json:"validations,omitempty"`
+	Variables       []celNamedExpression `json:"variables,omitempty"`
+	MatchConditions []celNamedExpression `json:"matchConditions,omitempty"`
+}
+
+type compiledValidation struct {
+	expression string
+	message    string
+	program    cel.Program
+	messageExp cel.Program
+}
+
+type compiledVariable struct {
+	name    string
+	program cel.Program
+}
+
+// celProgram is the compiled form of a celSource
+type celProgram struct {
+	variables       []compiledVariable
+	matchConditions []cel.Program
+	validations     []compiledValidation
+}
+
+// newCELEnv returns the environment of the expressions, it declares the same
+// variables as the Kubernetes admission control together with the extension
+// libraries available to the ValidatingAdmissionPolicies
+func newCELEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("oldObject", cel.DynType),
+		cel.Variable("params", cel.DynType),
+		cel.Variable("request", cel.DynType),
+		cel.Variable("namespaceObject", cel.DynType),
+		cel.Variable("variables", cel.MapType(cel.StringType, cel.DynType)),
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Sets(),
+		ext.Lists(),
+		ext.Math(),
+		ext.Encoders(),
+	)
+}
+
+func compileExpression(env *cel.Env, expression string, outputs ...*cel.Type) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("compiling expression %q: %w", expression, issues.Err())
+	}
+	if len(outputs) > 0 {
+		valid := ast.OutputType().IsExactType(cel.DynType)
+		for _, t := range outputs {
+			if ast.OutputType().IsExactType(t) {
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("expression %q must evaluate to %s, not %s", expression, outputs[0], ast.OutputType())
+		}
+	}
+	return env.Program(ast)
+}
+
+// compileCEL compiles the expressions of the source
+func compileCEL(src celSource) (*celProgram, error) {
+	env, err := newCELEnv()
+	if err != nil {
+		return nil, err
+	}
+	p := &celProgram{}
+	for _, v := range src.Variables {
+		prg, err := compileExpression(env, v.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", v.Name, err)
+		}
+		p.variables = append(p.variables, compiledVariable{name: v.Name, program: prg})
+	}
+	for _, c := range src.MatchConditions {
+		prg, err := compileExpression(env, c.Expression, cel.BoolType)
+		if err != nil {
+			return nil, fmt.Errorf("match condition %q: %w", c.Name, err)
+		}
+		p.matchConditions = append(p.matchConditions, prg)
+	}
+	for _, v := range src.Validations {
+		prg, err := compileExpression(env, v.Expression, cel.BoolType)
+		if err != nil {
+			return nil, err
+		}
+		compiled := compiledValidation{expression: v.Expression, message: v.Message, program: prg}
+		if v.MessageExpression != "" {
+			compiled.messageExp, err = compileExpression(env, v.MessageExpression, cel.StringType)
+			if err != nil {
+				return nil, err
+			}
+		}
+		p.validations = append(p.validations, compiled)
+	}
+	return p, nil
+}
+
+/*
+evaluate evaluates the expressions with the input variables, e.g. object and params,
+and returns the message of each failed validation
+  - the variables are evaluated in order, so that a variable can refer to the previous ones
+  - the object is not validated unless all the match conditions are true
+  - a validation which can't be evaluated is a failed validation
+*/
+func (p *celProgram) evaluate(input map[string]any, variables map[string]any) []string {
+	vars := map[string]any{}
+	for k, v := range variables {
+		vars[k] = v
+	}
+	activation := map[string]any{"variables": vars}
+	for k, v := range input {
+		activation[k] = v
+	}
+	for _, v := range p.variables {
+		val, _, err := v.program.Eval(activation)
+		if err != nil {
+			// the error is reported by the expressions which use the variable
+			val = types.WrapErr(err)
+		}
+		vars[v.name] = val
+	}
+
+	for _, c := range p.matchConditions {
+		val, _, err := c.Eval(activation)
+		if err != nil {
+			return []string{fmt.Sprintf("failed to evaluate match condition: %s", err)}
+		}
+		if val != types.True {
+			return nil
+		}
+	}
+
+	var messages []string
+	for _, v := range p.validations {
+		val, _, err := v.program.Eval(activation)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("failed to evaluate expression %q: %s", v.expression, err))
+			continue
+		}
+		if val == types.True {
+			continue
+		}
+		messages = append(messages, v.failureMessage(activation))
+	}
+	return messages
+}
+
+// failureMessage returns the messageExpression, falling back to the message and
+// to the expression itself like the Kubernetes admission control
+func (v compiledValidation) failureMessage(activation map[string]any) string {
+	if v.messageExp != nil {
+		if val, _, err := v.messageExp.Eval(activation); err == nil {
+			if msg, ok := val.Value().(string); ok && msg != "" {
+				return msg
+			}
+		}
+	}
+	if v.message != "" {
+		return v.message
+	}
+	return fmt.Sprintf("failed expression: %s", v.expression)
+}
+
+// celTemplate is a ConstraintTemplate with the K8sNativeValidation engine
+type celTemplate struct {
+	name    string
+	kind    string
+	program *celProgram
+}
+
+type constraintTemplateSpec struct {
+	CRD struct {
+		Spec struct {
+			Names struct {
+				Kind string `json:"kind"`
+			} `json:"names"`
+		} `json:"spec"`
+	} `json:"crd"`
+	Targets []struct {
+		Code []struct {
+			Engine string          `json:"engine"`
+			Source json.RawMessage `json:"source"`
+		} `json:"code"`
+	} `json:"targets"`
+}
+
+// decodeSpec decodes the spec of the object into out
+func decodeSpec(u *unstructured.Unstructured, out interface{}) error {
+	spec, _, _ := unstructured.NestedFieldNoCopy(u.Object, "spec")
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding spec of %s %q: %w", u.GetKind(), u.GetName(), err)
+	}
+	return nil
+}
+
+func isConstraintTemplate(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().Group == templatesGroup && u.GetKind() == "ConstraintTemplate"
+}
+
+func isConstraint(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().Group == constraintsGroup
+}
+
+// celTemplates returns the ConstraintTemplates of the objects which have code
+// for the K8sNativeValidation engine, by the kind of their constraints
+func celTemplates(objects []*unstructured.Unstructured) (map[string]*celTemplate, error) {
+	templates := map[string]*celTemplate{}
+	for _, u := range objects {
+		if !isConstraintTemplate(u) {
+			continue
+		}
+		var spec constraintTemplateSpec
+		if err := decodeSpec(u, &spec); err != nil {
+			return nil, err
+		}
+		for _, target := range spec.Targets {
+			for _, code := range target.Code {
+				if code.Engine != celEngine {
+					continue
+				}
+				var src celSource
+				if err := json.Unmarshal(code.Source, &src); err != nil {
+					return nil, fmt.Errorf("decoding %s source of ConstraintTemplate %q: %w", celEngine, u.GetName(), err)
+				}
+				prg, err := compileCEL(src)
+				if err != nil {
+					return nil, fmt.Errorf("ConstraintTemplate %q: %w", u.GetName(), err)
+				}
+				templates[spec.CRD.Spec.Names.Kind] = &celTemplate{
+					name:    u.GetName(),
+					kind:    spec.CRD.Spec.Names.Kind,
+					program: prg,
+				}
+			}
+		}
+	}
+	return templates, nil
+}
+
+// isPolicyObject checks if the object defines a policy or a mutation rather
+// than being a resource subject to the policies
+func isPolicyObject(u *unstructured.Unstructured) bool {
+	return isConstraintTemplate(u) || isConstraint(u) || isMutation(u) ||
+		isValidatingAdmissionPolicy(u) || isValidatingAdmissionPolicyBinding(u)
+}
+
+// namespaceOf returns the namespace of the object, which is looked up in the
+// objects, or nil if the object is cluster-scoped
+func namespaceOf(u *unstructured.Unstructured, objects []*unstructured.Unstructured) (*corev1.Namespace, error) {
+	name := u.GetNamespace()
+	if u.GroupVersionKind().Group == "" && u.GetKind() == "Namespace" {
+		name = u.GetName()
+	}
+	if name == "" {
+		return nil, nil
+	}
+	for _, o := range objects {
+		if o.GroupVersionKind().Group == "" && o.GetKind() == "Namespace" && o.GetName() == name {
+			ns := &corev1.Namespace{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, ns); err != nil {
+				return nil, fmt.Errorf("converting Namespace %q: %w", name, err)
+			}
+			return ns, nil
+		}
+	}
+	ns := &corev1.Namespace{}
+	ns.Name = name
+	return ns, nil
+}
+
+// celInput returns the variables of the admission request of the object
+func celInput(u *unstructured.Unstructured, ns *corev1.Namespace) (map[string]any, error) {
+	gvk := u.GroupVersionKind()
+	var nsObject any
+	if ns != nil && u.GetNamespace() != "" {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ns)
+		if err != nil {
+			return nil, err
+		}
+		nsObject = obj
+	}
+	return map[string]any{
+		"object":    u.Object,
+		"oldObject": nil,
+		"params":    nil,
+		"request": map[string]any{
+			"kind":      map[string]any{"group": gvk.Group, "version": gvk.Version, "kind": gvk.Kind},
+			"name":      u.GetName(),
+			"namespace": u.GetNamespace(),
+			"operation": "CREATE",
+			"object":    u.Object,
+		},
+		"namespaceObject": nsObject,
+	}, nil
+}
+
+// celResult is a failed validation of an object
+type celResult struct {
+	object            *unstructured.Unstructured
+	message           string
+	enforcementAction string
+}
+
+// evaluateCELTemplates evaluates the constraints of the templates against the
+// objects they match
+func evaluateCELTemplates(templates map[string]*celTemplate, objects []*unstructured.Unstructured) ([]celResult, error) {
+	var results []celResult
+	for _, constraint := range objects {
+		tmpl, found := templates[constraint.GetKind()]
+		if !isConstraint(constraint) || !found {
+			continue
+		}
+		m := &match.Match{}
+		if spec, found, _ := unstructured.NestedMap(constraint.Object, "spec", "match"); found {
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, m); err != nil {
+				return nil, fmt.Errorf("decoding match of constraint %q: %w", constraint.GetName(), err)
+			}
+		}
+		params, _, _ := unstructured.NestedFieldNoCopy(constraint.Object, "spec", "parameters")
+		action, _, _ := unstructured.NestedString(constraint.Object, "spec", "enforcementAction")
+
+		for _, u := range objects {
+			if isPolicyObject(u) {
+				continue
+			}
+			ns, err := namespaceOf(u, objects)
+			if err != nil {
+				return nil, err
+			}
+			matches, err := match.Matches(m, u, ns)
+			if err != nil {
+				return nil, fmt.Errorf("matching constraint %q: %w", constraint.GetName(), err)
+			}
+			if !matches {
+				continue
+			}
+			input, err := celInput(u, ns)
+			if err != nil {
+				return nil, err
+			}
+			input["params"] = params
+			// Gatekeeper exposes the parameters and the object as variables.params
+			// and variables.anyObject
+			for _, msg := range tmpl.program.evaluate(input, map[string]any{"params": params, "anyObject": u.Object}) {
+				results = append(results, celResult{
+					object:            u,
+					message:           violationMessage(msg, "violatedConstraint", constraint),
+					enforcementAction: action,
+				})
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+const celPolicy = `
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    code:
+    - engine: K8sNativeValidation
+      source:
+        variables:
+        - name: labels
+          expression: 'has(object.metadata.labels) ? object.metadata.labels : {}'
+        validations:
+        - expression: 'variables.params.labels.all(l, l in variables.labels)'
+          messageExpression: '"missing required labels: " + variables.params.labels.filter(l, !(l in variables.labels)).join(", ")'
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  enforcementAction: warn
+  match:
+    kinds:
+    - apiGroups: [""]
+      kinds: ["ConfigMap"]
+  parameters:
+    labels: ["owner"]
+`
+
+const vapPolicy = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: max-replicas
+spec:
+  paramKind:
+    apiVersion: v1
+    kind: ConfigMap
+  matchConstraints:
+    resourceRules:
+    - apiGroups: ["apps"]
+      apiVersions: ["v1"]
+      operations: ["CREATE", "UPDATE"]
+      resources: ["deployments"]
+  validations:
+  - expression: 'object.spec.replicas <= int(params.data.maxReplicas)'
+    message: 'too many replicas'
+  - expression: 'object.metadata.name.startsWith("app-")'
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: max-replicas
+spec:
+  policyName: max-replicas
+  validationActions: [Warn, Audit]
+  paramRef:
+    name: limits
+    namespace: default
+    parameterNotFoundAction: Deny
+  matchResources:
+    namespaceSelector:
+      matchLabels:
+        env: prod
+`
+
+const resources = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+  labels:
+    env: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: limits
+  namespace: default
+  labels:
+    owner: platform
+data:
+  maxReplicas: "3"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unowned
+  namespace: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-frontend
+  namespace: default
+spec:
+  replicas: 2
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: default
+spec:
+  replicas: 5
+`
+
+func parseObjects(t *testing.T, docs ...string) []*unstructured.Unstructured {
+	var objects []*unstructured.Unstructured
+	for _, doc := range docs {
+		nodes, err := kio.FromBytes([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range nodes {
+			s, err := n.String()
+			if err != nil {
+				t.Fatal(err)
+			}
+			u := &unstructured.Unstructured{}
+			if err := k8syaml.Unmarshal([]byte(s), u); err != nil {
+				t.Fatal(err)
+			}
+			objects = append(objects, u)
+		}
+	}
+	return objects
+}
+
+func resourceRef(apiVersion, kind, name, namespace string) yaml.ResourceIdentifier {
+	return yaml.ResourceIdentifier{
+		TypeMeta: yaml.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		NameMeta: yaml.NameMeta{Name: name, Namespace: namespace},
+	}
+}
+
+func TestValidateCEL(t *testing.T) {
+	testcases := []struct {
+		name   string
+		input  []string
+		cfg    *Gatekeeper
+		output *framework.Result
+	}{
+		{
+			name:  "K8sNativeValidation template",
+			input: []string{celPolicy, resources},
+			cfg:   &Gatekeeper{},
+			output: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "missing required labels: owner\nviolatedConstraint: require-owner",
+						Severity:    framework.Warning,
+						ResourceRef: resourceRef("v1", "ConfigMap", "unowned", "default"),
+					},
+				},
+			},
+		},
+		{
+			name:   "ValidatingAdmissionPolicies are not evaluated by default",
+			input:  []string{vapPolicy, resources},
+			cfg:    &Gatekeeper{},
+			output: nil,
+		},
+		{
+			name:  "ValidatingAdmissionPolicies",
+			input: []string{vapPolicy, resources},
+			cfg:   &Gatekeeper{ValidatingAdmissionPolicies: true},
+			output: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "failed expression: object.metadata.name.startsWith(\"app-\")\nviolatedPolicy: max-replicas",
+						Severity:    framework.Warning,
+						ResourceRef: resourceRef("apps/v1", "Deployment", "backend", "default"),
+					},
+					{
+						Message:     "too many replicas\nviolatedPolicy: max-replicas",
+						Severity:    framework.Warning,
+						ResourceRef: resourceRef("apps/v1", "Deployment", "backend", "default"),
+					},
+				},
+			},
+		},
+		{
+			name: "ValidatingAdmissionPolicy without params",
+			input: []string{vapPolicy, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+  labels:
+    env: prod
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app-frontend
+  namespace: default
+spec:
+  replicas: 2
+`},
+			cfg: &Gatekeeper{ValidatingAdmissionPolicies: true},
+			output: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "no params found for ValidatingAdmissionPolicyBinding \"max-replicas\"\nviolatedPolicy: max-replicas",
+						Severity:    framework.Warning,
+						ResourceRef: resourceRef("apps/v1", "Deployment", "app-frontend", "default"),
+					},
+				},
+			},
+		},
+		{
+			name: "ValidatingAdmissionPolicy without resourceRules matches nothing",
+			input: []string{`
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: deny-all
+spec:
+  matchConstraints:
+    namespaceSelector:
+      matchLabels:
+        env: prod
+  validations:
+  - expression: 'false'
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: deny-all
+spec:
+  policyName: deny-all
+  validationActions: [Deny]
+`, resources},
+			cfg:    &Gatekeeper{ValidatingAdmissionPolicies: true},
+			output: nil,
+		},
+		{
+			name: "custom resources matched by the plural of their CRD",
+			input: []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: cacti.example.com
+spec:
+  group: example.com
+  names:
+    kind: Cactus
+    plural: cacti
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: no-spiky-cacti
+spec:
+  matchConstraints:
+    resourceRules:
+    - apiGroups: ["example.com"]
+      apiVersions: ["*"]
+      operations: ["CREATE"]
+      resources: ["cacti"]
+  validations:
+  - expression: 'false'
+    message: 'spiky cactus'
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: no-spiky-cacti
+spec:
+  policyName: no-spiky-cacti
+  validationActions: [Deny]
+  matchResources:
+    objectSelector:
+      matchLabels:
+        spiky: "true"
+---
+apiVersion: example.com/v1
+kind: Cactus
+metadata:
+  name: saguaro
+  labels:
+    spiky: "true"
+---
+apiVersion: example.com/v1
+kind: Cactus
+metadata:
+  name: smooth
+`},
+			cfg: &Gatekeeper{ValidatingAdmissionPolicies: true},
+			output: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "spiky cactus\nviolatedPolicy: no-spiky-cacti",
+						Severity:    framework.Error,
+						ResourceRef: resourceRef("example.com/v1", "Cactus", "saguaro", ""),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Validate(parseObjects(t, tc.input...), tc.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.output, result) {
+				t.Errorf("expected:\n%+v\ngot:\n%+v", tc.output, result)
+			}
+		})
+	}
+}
+
+func TestResourceName(t *testing.T) {
+	names := resourceNames{{Group: "example.com", Kind: "Cactus"}: "cacti"}
+	for gvk, expected := range map[schema.GroupVersionKind]string{
+		{Group: "apps", Version: "v1", Kind: "Deployment"}:                   "deployments",
+		{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}:         "ingresses",
+		{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"}:   "networkpolicies",
+		{Version: "v1", Kind: "Endpoints"}:                                   "endpoints",
+		{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"}: "gateways",
+		{Group: "example.com", Version: "v1", Kind: "Cactus"}:                "cacti",
+		{Group: "example.org", Version: "v1", Kind: "Cactus"}:                "cactuses",
+	} {
+		if got := names.resourceName(gvk); got != expected {
+			t.Errorf("resourceName(%v): expected %q, got %q", gvk, expected, got)
+		}
+	}
+}
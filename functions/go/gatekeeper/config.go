@@ -0,0 +1,85 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	fnConfigAPIVersion = "fn.kpt.dev/v1alpha1"
+	fnConfigKind       = "Gatekeeper"
+
+	// ModeValidate validates the resources against the policies
+	ModeValidate = "validate"
+	// ModeMutate applies the mutations to the resources before validating them
+	ModeMutate = "mutate"
+	// ModeVerify runs the gator Suites of the package and of the suite directories
+	ModeVerify = "verify"
+)
+
+// Gatekeeper is the functionConfig of the function
+type Gatekeeper struct {
+	yaml.ResourceMeta `json:",inline" yaml:",inline"`
+
+	// Mode is the mode of the function, validate by default
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+
+	// PolicyDirs are the local directories of ConstraintTemplates, Constraints and
+	// ValidatingAdmissionPolicies to enforce in addition to the policies of the package
+	PolicyDirs []string `json:"policyDirs,omitempty" yaml:"policyDirs,omitempty"`
+
+	// SuiteDirs are the local directories of gator Suites to run in verify mode
+	// in addition to the Suites of the package
+	SuiteDirs []string `json:"suiteDirs,omitempty" yaml:"suiteDirs,omitempty"`
+
+	// ValidatingAdmissionPolicies evaluates the ValidatingAdmissionPolicies and
+	// ValidatingAdmissionPolicyBindings in the package
+	ValidatingAdmissionPolicies bool `json:"validatingAdmissionPolicies,omitempty" yaml:"validatingAdmissionPolicies,omitempty"`
+
+	// Sync lists the kinds of the resources available to the templates in
+	// data.inventory, all the resources are available if it's empty
+	Sync *Sync `json:"sync,omitempty" yaml:"sync,omitempty"`
+}
+
+// getConfig decodes the functionConfig, any functionConfig other than a
+// Gatekeeper resource is ignored and the defaults are used
+func getConfig(fc *yaml.RNode) (*Gatekeeper, error) {
+	cfg := &Gatekeeper{Mode: ModeValidate}
+	if fc == nil || fc.GetApiVersion() != fnConfigAPIVersion || fc.GetKind() != fnConfigKind {
+		return cfg, nil
+	}
+	s, err := fc.String()
+	if err != nil {
+		return nil, err
+	}
+	d := yaml.NewDecoder(strings.NewReader(s))
+	d.KnownFields(true)
+	if err := d.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s functionConfig: %w", fnConfigKind, err)
+	}
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = ModeValidate
+	case ModeValidate, ModeMutate, ModeVerify:
+	default:
+		return nil, fmt.Errorf("invalid %s functionConfig: unknown mode %q, must be one of %s, %s or %s",
+			fnConfigKind, cfg.Mode, ModeValidate, ModeMutate, ModeVerify)
+	}
+	return cfg, nil
+}
@@ -23,12 +23,14 @@ There are 2 kinds of resources needed to define a policy, and they need to be
 provided using ` + "`" + `input items` + "`" + ` along with other KRM resources to be validated.
 
 - [Constraint Template]: Define the schema and logic of a policy. The policy
-  logic in a Constraint Template must be written in the [Rego] language.
+  logic in a Constraint Template is written in the [Rego] language or in [CEL]
+  with the ` + "`" + `K8sNativeValidation` + "`" + ` engine.
 - [Constraint]: Signal the Gatekeeper the corresponding constraints need to be
   enforced. Every Constraint must be backed by a Constraint Template.
 
 The constraint templates and the constraints resources should be in the same
-package containing the KRM resources.
+package containing the KRM resources, or in a policy library directory mounted
+into the function container, see [Policy library](#policy-library).
 
 The following is a ` + "`" + `ConstraintTemplate` + "`" + `:
 
@@ -77,4 +79,178 @@ above.
             - 'apps'
           kinds:
             - Deployment
+
+### Results
+
+Each violation is reported as a result whose message is followed by the name
+of the violated constraint. The severity of the result depends on the
+` + "`" + `enforcementAction` + "`" + ` of the constraint: ` + "`" + `deny` + "`" + ` is an error, ` + "`" + `warn` + "`" + ` a warning and
+` + "`" + `dryrun` + "`" + ` an info.
+
+When the ` + "`" + `details` + "`" + ` of a Rego violation have a ` + "`" + `field` + "`" + ` or ` + "`" + `path` + "`" + ` key, it is
+reported in ` + "`" + `field.path` + "`" + ` of the result, together with the ` + "`" + `currentValue` + "`" + ` and the
+` + "`" + `proposedValue` + "`" + ` or ` + "`" + `suggestedValue` + "`" + ` of the details, so that IDEs and kpt point at
+the violating field:
+
+  violation[{"msg": msg, "details": details}] {
+    replicas := input.review.object.spec.replicas
+    replicas > input.parameters.max
+    msg := sprintf("%v replicas are too many", [replicas])
+    details := {"field": "spec.replicas", "currentValue": replicas, "proposedValue": input.parameters.max}
+  }
+
+The ` + "`" + `gatekeeper.kpt.dev/remediation` + "`" + ` annotation of a constraint, or of a
+` + "`" + `ValidatingAdmissionPolicy` + "`" + `, carries a remediation hint or a link to the docs
+which is added to the message of its violations:
+
+  apiVersion: constraints.gatekeeper.sh/v1beta1
+  kind: K8sMaxReplicas
+  metadata:
+    name: max-replicas
+    annotations:
+      gatekeeper.kpt.dev/remediation: https://example.com/docs/replicas
+
+### CEL
+
+A ` + "`" + `ConstraintTemplate` + "`" + ` whose code uses the ` + "`" + `K8sNativeValidation` + "`" + ` engine is
+evaluated with [CEL] instead of Rego. The expressions can use the ` + "`" + `object` + "`" + `,
+` + "`" + `params` + "`" + `, ` + "`" + `namespaceObject` + "`" + ` and ` + "`" + `request` + "`" + ` variables, the parameters of the
+constraint are also available as ` + "`" + `variables.params` + "`" + `.
+
+  apiVersion: templates.gatekeeper.sh/v1
+  kind: ConstraintTemplate
+  metadata:
+    name: k8srequiredlabels
+  spec:
+    crd:
+      spec:
+        names:
+          kind: K8sRequiredLabels
+    targets:
+      - target: admission.k8s.gatekeeper.sh
+        code:
+          - engine: K8sNativeValidation
+            source:
+              validations:
+                - expression: 'variables.params.labels.all(l, has(object.metadata.labels) && l in object.metadata.labels)'
+                  message: 'missing required labels'
+
+### ValidatingAdmissionPolicies
+
+The function can also evaluate the ` + "`" + `ValidatingAdmissionPolicy` + "`" + ` and
+` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + ` resources of the package, so that policies
+moved to the Kubernetes native admission control are still checked at render
+time. This is enabled with a ` + "`" + `Gatekeeper` + "`" + ` function configuration:
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Gatekeeper
+  metadata:
+    name: gatekeeper
+  validatingAdmissionPolicies: true
+
+Each binding evaluates its policy against the resources matched by the
+` + "`" + `matchConstraints` + "`" + ` of the policy and the ` + "`" + `matchResources` + "`" + ` of the binding, with
+the params of the ` + "`" + `paramRef` + "`" + ` found in the package. Like the API server, a policy
+without ` + "`" + `resourceRules` + "`" + ` in its ` + "`" + `matchConstraints` + "`" + ` matches nothing, while a binding
+without ` + "`" + `resourceRules` + "`" + ` doesn't restrict the resources matched by its policy. The
+resources are matched as if they were created. Their resource names are the plurals of
+the ` + "`" + `CustomResourceDefinition` + "`" + ` of their kinds if it is in the package, otherwise they are
+guessed from their kinds since there is no discovery at render time, e.g. ` + "`" + `networkpolicies` + "`" + `
+for ` + "`" + `NetworkPolicy` + "`" + `. An irregular plural of a kind whose CRD isn't in the package is not
+matched unless the CRD is added to the package. The ` + "`" + `validationActions` + "`" + ` of the
+binding give the severity of the results: ` + "`" + `Deny` + "`" + ` is an error, ` + "`" + `Warn` + "`" + ` a warning
+and ` + "`" + `Audit` + "`" + ` an info.
+
+### Policy library
+
+Instead of copying the policies into every package, the ` + "`" + `ConstraintTemplate` + "`" + `,
+constraint, mutation, ` + "`" + `ValidatingAdmissionPolicy` + "`" + ` and ` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + `
+resources can be loaded from local directories with the ` + "`" + `policyDirs` + "`" + ` field of
+the ` + "`" + `Gatekeeper` + "`" + ` function configuration. The YAML and JSON files of the
+directories and their subdirectories are read once, the other resources of the
+files are ignored.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Gatekeeper
+  metadata:
+    name: gatekeeper
+  policyDirs:
+    - /policies
+
+The directories must be mounted into the container, e.g. with the ` + "`" + `--mount` + "`" + `
+flag of ` + "`" + `kpt fn eval` + "`" + `:
+
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/gatekeeper:latest \
+    --mount type=bind,src="$(pwd)/policies",dst=/policies --fn-config gatekeeper.yaml
+
+The policies of the directories are merged with the policies of the package,
+a policy of the package takes precedence over a policy of a directory with the
+same kind and name. The policies of the directories are not added to the
+output of the function.
+
+### Referential constraints
+
+Templates can reference the other resources of the package in ` + "`" + `data.inventory` + "`" + `,
+e.g. to require unique ingress hosts, like the resources synced into OPA by
+Gatekeeper. All the resources are available by default. The ` + "`" + `sync` + "`" + ` field of the
+` + "`" + `Gatekeeper` + "`" + ` function configuration restricts them to the listed kinds, like the
+sync list of the Gatekeeper ` + "`" + `Config` + "`" + `; an entry without a version matches all the
+versions of its kind.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Gatekeeper
+  metadata:
+    name: gatekeeper
+  sync:
+    syncOnly:
+      - group: networking.k8s.io
+        version: v1
+        kind: Ingress
+      - version: v1
+        kind: Namespace
+
+All the resources are validated, whether they are synced or not.
+
+### Mutation
+
+With ` + "`" + `mode: mutate` + "`" + ` in the ` + "`" + `Gatekeeper` + "`" + ` function configuration, the function
+applies the Gatekeeper [mutation] resources of the package, ` + "`" + `Assign` + "`" + `,
+` + "`" + `AssignImage` + "`" + `, ` + "`" + `AssignMetadata` + "`" + ` and ` + "`" + `ModifySet` + "`" + `, to the other resources before
+validating them, so that the output of the function is the same as the
+resources admitted by a cluster running Gatekeeper mutation.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Gatekeeper
+  metadata:
+    name: gatekeeper
+  mode: mutate
+
+Like the Gatekeeper webhook, the mutations are applied in the order of their
+kinds and names until the resource doesn't change anymore. Each applied mutation
+is reported as an ` + "`" + `info` + "`" + ` result with the mutated location in ` + "`" + `field.path` + "`" + `, e.g.
+` + "`" + `mutated by Assign always-pull` + "`" + `. A resource which can't be mutated is reported
+as an error and is left unchanged. Only the mutated fields of a resource are
+rewritten, the other fields keep their comments, e.g. the ` + "`" + `kpt-set` + "`" + ` setters, and
+their order. The default mode is ` + "`" + `validate` + "`" + `, which only validates the resources.
+
+### Policy tests
+
+With ` + "`" + `mode: verify` + "`" + ` in the ` + "`" + `Gatekeeper` + "`" + ` function configuration, the function
+runs the [gator Suites][gator verify] of the package instead of validating the
+resources, like ` + "`" + `gator verify` + "`" + `. The paths of the templates, constraints and
+objects of a ` + "`" + `Suite` + "`" + ` are relative to its file, and are looked up in the files of
+the package. Suites of local directories, e.g. mounted with the ` + "`" + `--mount` + "`" + ` flag
+of ` + "`" + `kpt fn eval` + "`" + `, are run as well with the ` + "`" + `suiteDirs` + "`" + ` field.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Gatekeeper
+  metadata:
+    name: gatekeeper
+  mode: verify
+  suiteDirs:
+    - /policies
+
+Each test case is reported as an ` + "`" + `info` + "`" + ` result if it passes and as an ` + "`" + `error` + "`" + `
+otherwise, e.g. ` + "`" + `test "no-debug" case "debug-label" passed` + "`" + `, with the path of
+the ` + "`" + `Suite` + "`" + ` file in ` + "`" + `file.path` + "`" + `. The resources of the package are not changed.
 `
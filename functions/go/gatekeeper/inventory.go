@@ -0,0 +1,131 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/open-policy-agent/frameworks/constraint/pkg/apis"
+	opatypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
+	"github.com/open-policy-agent/gatekeeper/pkg/gator"
+	// The gatekeeper/pkg/gator/test package is the underlying libraries for
+	// the `gator test` subcommand, not a library for testing golang code.
+	gatortest "github.com/open-policy-agent/gatekeeper/pkg/gator/test"
+	"github.com/open-policy-agent/gatekeeper/pkg/target"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	if err := apis.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+}
+
+// Sync lists the kinds of the objects available to the templates in
+// data.inventory, like the sync list of the Gatekeeper Config
+type Sync struct {
+	SyncOnly []SyncOnlyEntry `json:"syncOnly,omitempty" yaml:"syncOnly,omitempty"`
+}
+
+// SyncOnlyEntry is a kind of synced objects, an empty version matches all
+// the versions of the kind
+type SyncOnlyEntry struct {
+	Group   string `json:"group,omitempty" yaml:"group,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Kind    string `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// Matches returns whether the object is of a synced kind
+func (s *Sync) Matches(u *unstructured.Unstructured) bool {
+	gvk := u.GroupVersionKind()
+	for _, e := range s.SyncOnly {
+		if e.Group == gvk.Group && e.Kind == gvk.Kind && (e.Version == "" || e.Version == gvk.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+reviewRego evaluates the Rego templates and constraints among the objects
+  - without a sync list, all the objects are added to data.inventory and
+    audited like by `gator test`
+  - with a sync list, only the objects of the synced kinds are added to
+    data.inventory, and each object is reviewed against the constraints
+*/
+func reviewRego(objects []*unstructured.Unstructured, sync *Sync) ([]*opatypes.Result, error) {
+	if sync == nil || len(sync.SyncOnly) == 0 {
+		resps, err := gatortest.Test(objects)
+		if err != nil {
+			return nil, err
+		}
+		return resps.Results(), nil
+	}
+
+	client, err := gator.NewOPAClient()
+	if err != nil {
+		return nil, fmt.Errorf("creating OPA client: %w", err)
+	}
+	ctx := context.Background()
+	// a constraint must be added after its template
+	for _, u := range objects {
+		if !isConstraintTemplate(u) {
+			continue
+		}
+		templ, err := gator.ToTemplate(scheme, u)
+		if err != nil {
+			return nil, fmt.Errorf("converting unstructured %q to template: %w", u.GetName(), err)
+		}
+		if _, err := client.AddTemplate(templ); err != nil {
+			return nil, fmt.Errorf("adding template %q: %w", templ.GetName(), err)
+		}
+	}
+	for _, u := range objects {
+		if !isConstraint(u) {
+			continue
+		}
+		if _, err := client.AddConstraint(ctx, u); err != nil {
+			return nil, fmt.Errorf("adding constraint %q: %w", u.GetName(), err)
+		}
+	}
+	for _, u := range objects {
+		if !sync.Matches(u) {
+			continue
+		}
+		if _, err := client.AddData(ctx, u); err != nil {
+			return nil, fmt.Errorf("adding data of GVK %q: %w", u.GroupVersionKind().String(), err)
+		}
+	}
+
+	var results []*opatypes.Result
+	for _, u := range objects {
+		// the namespace of the package is passed along the object, so that the
+		// namespace selectors match whether the Namespace is synced or not
+		ns, err := namespaceOf(u, objects)
+		if err != nil {
+			return nil, err
+		}
+		resps, err := client.Review(ctx, &target.AugmentedUnstructured{Object: *u, Namespace: ns})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing %s %q: %w", u.GetKind(), u.GetName(), err)
+		}
+		results = append(results, resps.Results()...)
+	}
+	return results, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const uniqueIngressHost = `
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8suniqueingresshost
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sUniqueIngressHost
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |
+      package k8suniqueingresshost
+
+      identical(obj, review) {
+        obj.metadata.namespace == review.object.metadata.namespace
+        obj.metadata.name == review.object.metadata.name
+      }
+
+      violation[{"msg": msg}] {
+        input.review.kind.kind == "Ingress"
+        host := input.review.object.spec.rules[_].host
+        other := data.inventory.namespace[_][otherapiversion]["Ingress"][name]
+        re_match("^(extensions|networking.k8s.io)/.+$", otherapiversion)
+        other.spec.rules[_].host == host
+        not identical(other, input.review)
+        msg := sprintf("ingress host conflicts with an existing ingress <%v>", [host])
+      }
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sUniqueIngressHost
+metadata:
+  name: unique-ingress-host
+spec:
+  match:
+    kinds:
+    - apiGroups: ["networking.k8s.io"]
+      kinds: ["Ingress"]
+`
+
+const ingresses = `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: frontend
+  namespace: default
+spec:
+  rules:
+  - host: example.com
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: backend
+  namespace: backend
+spec:
+  rules:
+  - host: example.com
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: docs
+  namespace: default
+spec:
+  rules:
+  - host: docs.example.com
+`
+
+func TestValidateInventory(t *testing.T) {
+	conflicts := &framework.Result{
+		Items: []framework.ResultItem{
+			{
+				Message:     "ingress host conflicts with an existing ingress <example.com>\nviolatedConstraint: unique-ingress-host",
+				Severity:    framework.Error,
+				ResourceRef: resourceRef("networking.k8s.io/v1", "Ingress", "backend", "backend"),
+			},
+			{
+				Message:     "ingress host conflicts with an existing ingress <example.com>\nviolatedConstraint: unique-ingress-host",
+				Severity:    framework.Error,
+				ResourceRef: resourceRef("networking.k8s.io/v1", "Ingress", "frontend", "default"),
+			},
+		},
+	}
+
+	testcases := []struct {
+		name   string
+		config string
+		output *framework.Result
+	}{
+		{
+			name:   "all the resources are synced by default",
+			output: conflicts,
+		},
+		{
+			name: "synced kind",
+			config: `
+sync:
+  syncOnly:
+  - group: networking.k8s.io
+    version: v1
+    kind: Ingress
+  - version: v1
+    kind: Namespace
+`,
+			output: conflicts,
+		},
+		{
+			name: "synced kind of any version",
+			config: `
+sync:
+  syncOnly:
+  - group: networking.k8s.io
+    kind: Ingress
+`,
+			output: conflicts,
+		},
+		{
+			name: "kind which isn't synced",
+			config: `
+sync:
+  syncOnly:
+  - group: networking.k8s.io
+    version: v1beta1
+    kind: Ingress
+`,
+			output: nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := getConfig(yaml.MustParse(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Gatekeeper
+metadata:
+  name: gatekeeper
+` + tc.config))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := Validate(parseObjects(t, uniqueIngressHost, ingresses), cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.output, result) {
+				t.Errorf("expected:\n%+v\ngot:\n%+v", tc.output, result)
+			}
+		})
+	}
+}
@@ -21,7 +21,6 @@ import (
 
 	"github.com/kptdev/krm-functions-catalog/functions/go/gatekeeper/generated"
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	k8syaml "sigs.k8s.io/yaml"
@@ -45,23 +44,50 @@ func (gkp *GatekeeperProcessor) Process(resourceList *framework.ResourceList) er
 	resourceList.Result = &framework.Result{
 		Name: "gatekeeper",
 	}
-	var objects []*unstructured.Unstructured
-	for _, item := range resourceList.Items {
-		s, err := item.String()
+	objects, err := toUnstructured(resourceList.Items)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := getConfig(resourceList.FunctionConfig)
+	if err != nil {
+		return err
+	}
+	if cfg.Mode == ModeVerify {
+		// the policies are tested by the Suites, the items are left unchanged
+		items, err := Verify(resourceList.Items, cfg.SuiteDirs)
 		if err != nil {
 			return err
 		}
+		resourceList.Result = &framework.Result{Items: items}
+		if resultContainsError(resourceList.Result) {
+			return resourceList.Result
+		}
+		return nil
+	}
+	// the policies of the directories are only used for the validation, they
+	// are not added to the items
+	policies, err := loadPolicies(cfg.PolicyDirs)
+	if err != nil {
+		return err
+	}
+	objects = mergePolicies(objects, policies)
 
-		un := &unstructured.Unstructured{}
-		err = k8syaml.Unmarshal([]byte(s), un)
+	var mutationItems []framework.ResultItem
+	if cfg.Mode == ModeMutate {
+		var mutated map[int]bool
+		mutated, mutationItems, err = Mutate(objects)
 		if err != nil {
 			return err
 		}
-
-		objects = append(objects, un)
+		for i := range mutated {
+			if err := updateRNode(resourceList.Items[i], objects[i]); err != nil {
+				return err
+			}
+		}
 	}
 
-	result, err := Validate(objects)
+	result, err := Validate(objects, cfg)
 	// When err is not nil, result should be nil.
 	if err != nil {
 		result = &framework.Result{
@@ -73,6 +99,13 @@ func (gkp *GatekeeperProcessor) Process(resourceList *framework.ResourceList) er
 			},
 		}
 	}
+	if len(mutationItems) > 0 {
+		if result == nil {
+			result = &framework.Result{}
+		}
+		result.Items = append(mutationItems, result.Items...)
+		sortResultItems(result.Items)
+	}
 	resourceList.Result = result
 	if resultContainsError(result) {
 		return result
@@ -0,0 +1,242 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	mutationsunversioned "github.com/open-policy-agent/gatekeeper/apis/mutations/unversioned"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/mutators"
+	"github.com/open-policy-agent/gatekeeper/pkg/mutation/types"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+)
+
+const mutationsGroup = "mutations.gatekeeper.sh"
+
+func isMutation(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().Group == mutationsGroup
+}
+
+// decodeObject decodes the object into out
+func decodeObject(u *unstructured.Unstructured, out interface{}) error {
+	data, err := json.Marshal(u.Object)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding %s %q: %w", u.GetKind(), u.GetName(), err)
+	}
+	return nil
+}
+
+// newMutator returns the mutator of a mutation object
+func newMutator(u *unstructured.Unstructured) (types.Mutator, error) {
+	switch u.GetKind() {
+	case "Assign":
+		a := &mutationsunversioned.Assign{}
+		if err := decodeObject(u, a); err != nil {
+			return nil, err
+		}
+		return mutators.MutatorForAssign(a)
+	case "AssignMetadata":
+		a := &mutationsunversioned.AssignMetadata{}
+		if err := decodeObject(u, a); err != nil {
+			return nil, err
+		}
+		return mutators.MutatorForAssignMetadata(a)
+	case "ModifySet":
+		m := &mutationsunversioned.ModifySet{}
+		if err := decodeObject(u, m); err != nil {
+			return nil, err
+		}
+		return mutators.MutatorForModifySet(m)
+	case "AssignImage":
+		a := &assignImage{}
+		if err := decodeObject(u, a); err != nil {
+			return nil, err
+		}
+		return mutatorForAssignImage(a)
+	default:
+		return nil, fmt.Errorf("unknown mutation kind %s", u.GetKind())
+	}
+}
+
+// newMutators returns the mutators of the mutation objects ordered by their ids
+// like in the Gatekeeper mutation system, and a result for each mutation object
+// which can't be applied
+func newMutators(objects []*unstructured.Unstructured) ([]types.Mutator, []framework.ResultItem, error) {
+	var items []framework.ResultItem
+	addItem := func(msg string, u *unstructured.Unstructured) error {
+		item, err := resultItem(msg, u, "")
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	}
+
+	// the mutation system detects the mutators which conflict with the schema
+	// of the others, e.g. one assigns a list and another one an object
+	system := mutation.NewSystem(mutation.SystemOpts{})
+	var ms []types.Mutator
+	objs := map[types.ID]*unstructured.Unstructured{}
+	for _, u := range objects {
+		if !isMutation(u) {
+			continue
+		}
+		m, err := newMutator(u)
+		if err != nil {
+			if err := addItem(err.Error(), u); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		// the conflicts are reported once all the mutators are added
+		_ = system.Upsert(m)
+		ms = append(ms, m)
+		objs[m.ID()] = u
+	}
+
+	var valid []types.Mutator
+	for _, m := range ms {
+		if conflicts := system.GetConflicts(m.ID()); len(conflicts) > 0 {
+			if err := addItem(fmt.Sprintf("%s has a schema conflicting with other mutations and is not applied", mutatorName(m.ID())), objs[m.ID()]); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		valid = append(valid, m)
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		a, b := valid[i].ID(), valid[j].ID()
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return valid, items, nil
+}
+
+/*
+Mutate applies the Gatekeeper mutations among the objects to the other objects
+in place, like the Gatekeeper mutation webhook
+  - the mutators are applied in order until the object doesn't change anymore
+  - the result of each applied mutation is an info
+  - an object which can't be mutated has an error result and is left unchanged
+
+It returns the indexes of the mutated objects.
+*/
+func Mutate(objects []*unstructured.Unstructured) (map[int]bool, []framework.ResultItem, error) {
+	ms, items, err := newMutators(objects)
+	if err != nil {
+		return nil, nil, err
+	}
+	mutated := map[int]bool{}
+	if len(ms) == 0 {
+		return mutated, items, nil
+	}
+
+	for i, u := range objects {
+		if isPolicyObject(u) {
+			continue
+		}
+		ns, err := namespaceOf(u, objects)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		obj := u.DeepCopy()
+		var applied []types.Mutator
+		var mutateErr error
+		converged := false
+		for iteration := 0; iteration <= len(ms) && mutateErr == nil && !converged; iteration++ {
+			old := obj.DeepCopy()
+			for _, m := range ms {
+				if !m.Matches(obj, ns) {
+					continue
+				}
+				changed, err := m.Mutate(obj)
+				if err != nil {
+					mutateErr = fmt.Errorf("mutation %s failed: %w", mutatorName(m.ID()), err)
+					break
+				}
+				if changed && !containsMutator(applied, m) {
+					applied = append(applied, m)
+				}
+			}
+			converged = reflect.DeepEqual(old.Object, obj.Object)
+		}
+
+		switch {
+		case mutateErr != nil:
+			item, err := resultItem(mutateErr.Error(), u, "")
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+			continue
+		case !converged:
+			item, err := resultItem("mutations are not converging", u, "")
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+
+		for _, m := range applied {
+			item, err := resultItem(fmt.Sprintf("mutated by %s", mutatorName(m.ID())), u, "")
+			if err != nil {
+				return nil, nil, err
+			}
+			item.Severity = framework.Info
+			item.Field = framework.Field{Path: m.Path().String()}
+			items = append(items, item)
+		}
+		if len(applied) > 0 {
+			objects[i] = obj
+			mutated[i] = true
+		}
+	}
+	return mutated, items, nil
+}
+
+// mutatorName returns the kind and name of the mutator, e.g. Assign always-pull
+func mutatorName(id types.ID) string {
+	if id.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", id.Kind, id.Namespace, id.Name)
+	}
+	return fmt.Sprintf("%s %s", id.Kind, id.Name)
+}
+
+func containsMutator(ms []types.Mutator, m types.Mutator) bool {
+	for _, e := range ms {
+		if e.ID() == m.ID() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const mutations = `
+apiVersion: mutations.gatekeeper.sh/v1beta1
+kind: Assign
+metadata:
+  name: always-pull
+spec:
+  applyTo:
+  - groups: [""]
+    kinds: ["Pod"]
+    versions: ["v1"]
+  match:
+    kinds:
+    - apiGroups: [""]
+      kinds: ["Pod"]
+  location: "spec.containers[name:*].imagePullPolicy"
+  parameters:
+    assign:
+      value: Always
+---
+apiVersion: mutations.gatekeeper.sh/v1beta1
+kind: AssignMetadata
+metadata:
+  name: owner
+spec:
+  match:
+    kinds:
+    - apiGroups: [""]
+      kinds: ["Pod"]
+  location: "metadata.labels.owner"
+  parameters:
+    assign:
+      value: platform
+---
+apiVersion: mutations.gatekeeper.sh/v1alpha1
+kind: AssignImage
+metadata:
+  name: registry
+spec:
+  applyTo:
+  - groups: [""]
+    kinds: ["Pod"]
+    versions: ["v1"]
+  match:
+    kinds:
+    - apiGroups: [""]
+      kinds: ["Pod"]
+  location: "spec.containers[name:*].image"
+  parameters:
+    assignDomain: registry.example.com
+`
+
+func TestProcessMutate(t *testing.T) {
+	items, err := kio.FromBytes([]byte(mutations + `
+---
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8srequiredlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sRequiredLabels
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    code:
+    - engine: K8sNativeValidation
+      source:
+        validations:
+        - expression: 'has(object.metadata.labels) && "owner" in object.metadata.labels'
+          message: missing owner label
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+---
+# the nginx pod
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+  namespace: default # kpt-set: ${namespace}
+spec:
+  containers:
+  # the web server
+  - name: nginx
+    image: nginx:1.25 # kpt-set: nginx:${tag}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name     string
+		mode     string
+		expected *framework.Result
+		pod      string
+	}{
+		{
+			name: "validate",
+			mode: ModeValidate,
+			expected: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "missing owner label\nviolatedConstraint: require-owner",
+						Severity:    framework.Error,
+						ResourceRef: resourceRef("v1", "Pod", "nginx", "default"),
+					},
+				},
+			},
+			pod: `# the nginx pod
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+  namespace: default # kpt-set: ${namespace}
+spec:
+  containers:
+    # the web server
+    - name: nginx
+      image: nginx:1.25 # kpt-set: nginx:${tag}
+`,
+		},
+		{
+			name: "mutate",
+			mode: ModeMutate,
+			expected: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:     "mutated by AssignMetadata owner",
+						Severity:    framework.Info,
+						ResourceRef: resourceRef("v1", "Pod", "nginx", "default"),
+						Field:       framework.Field{Path: "metadata.labels.owner"},
+					},
+					{
+						Message:     "mutated by AssignImage registry",
+						Severity:    framework.Info,
+						ResourceRef: resourceRef("v1", "Pod", "nginx", "default"),
+						Field:       framework.Field{Path: "spec.containers[name: *].image"},
+					},
+					{
+						Message:     "mutated by Assign always-pull",
+						Severity:    framework.Info,
+						ResourceRef: resourceRef("v1", "Pod", "nginx", "default"),
+						Field:       framework.Field{Path: "spec.containers[name: *].imagePullPolicy"},
+					},
+				},
+			},
+			// the comments and the order of the fields are kept
+			pod: `# the nginx pod
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+  namespace: default # kpt-set: ${namespace}
+  labels:
+    owner: platform
+spec:
+  containers:
+    # the web server
+    - name: nginx
+      image: registry.example.com/nginx:1.25 # kpt-set: nginx:${tag}
+      imagePullPolicy: Always
+`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var copied []*yaml.RNode
+			for _, item := range items {
+				copied = append(copied, item.Copy())
+			}
+			fc := yaml.MustParse(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Gatekeeper
+metadata:
+  name: gatekeeper
+mode: ` + tc.mode)
+			rl := &framework.ResourceList{Items: copied, FunctionConfig: fc}
+			_ = (&GatekeeperProcessor{}).Process(rl)
+			if !reflect.DeepEqual(tc.expected, rl.Result) {
+				t.Errorf("expected:\n%+v\ngot:\n%+v", tc.expected, rl.Result)
+			}
+			pod, err := rl.Items[len(rl.Items)-1].String()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pod != tc.pod {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.pod, pod)
+			}
+		})
+	}
+}
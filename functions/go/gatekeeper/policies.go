@@ -0,0 +1,240 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+// toUnstructured converts the nodes into unstructured objects
+func toUnstructured(nodes []*yaml.RNode) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	for _, item := range nodes {
+		s, err := item.String()
+		if err != nil {
+			return nil, err
+		}
+
+		un := &unstructured.Unstructured{}
+		err = k8syaml.Unmarshal([]byte(s), un)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, un)
+	}
+	return objects, nil
+}
+
+// updateRNode applies the changes of the mutated object to its node, the fields
+// which aren't changed keep their comments, style and order
+func updateRNode(node *yaml.RNode, u *unstructured.Unstructured) error {
+	return updateNode(node.YNode(), u.Object)
+}
+
+// updateNode updates the node to the value, the fields and the items which are
+// in both are updated recursively so that only the changed scalars are replaced
+func updateNode(node *yaml.Node, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if node.Kind != yaml.MappingNode {
+			return replaceNode(node, value)
+		}
+		fields := map[string]*yaml.Node{}
+		var content []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if _, found := v[key]; found {
+				fields[key] = node.Content[i+1]
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		node.Content = content
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if field, found := fields[key]; found {
+				if err := updateNode(field, v[key]); err != nil {
+					return err
+				}
+				continue
+			}
+			// the new fields are encoded as a map to quote the keys if needed
+			field, err := encodeNode(map[string]interface{}{key: v[key]})
+			if err != nil {
+				return err
+			}
+			node.Content = append(node.Content, field.Content...)
+		}
+		return nil
+	case []interface{}:
+		if node.Kind != yaml.SequenceNode {
+			return replaceNode(node, value)
+		}
+		for i, item := range v {
+			if i < len(node.Content) {
+				if err := updateNode(node.Content[i], item); err != nil {
+					return err
+				}
+				continue
+			}
+			n, err := encodeNode(item)
+			if err != nil {
+				return err
+			}
+			node.Content = append(node.Content, n)
+		}
+		node.Content = node.Content[:len(v)]
+		return nil
+	default:
+		if node.Kind == yaml.ScalarNode {
+			var current interface{}
+			if err := node.Decode(&current); err != nil {
+				return err
+			}
+			// the values are compared as JSON so that e.g. 1 and 1.0 are equal
+			same, err := jsonEqual(current, value)
+			if err != nil {
+				return err
+			}
+			if same {
+				return nil
+			}
+		}
+		return replaceNode(node, value)
+	}
+}
+
+// replaceNode replaces the node with the encoded value and keeps its comments
+func replaceNode(node *yaml.Node, value interface{}) error {
+	n, err := encodeNode(value)
+	if err != nil {
+		return err
+	}
+	n.HeadComment, n.LineComment, n.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *n
+	return nil
+}
+
+// encodeNode encodes the value into a node
+func encodeNode(value interface{}) (*yaml.Node, error) {
+	content, err := k8syaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	n, err := yaml.Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	return n.YNode(), nil
+}
+
+func jsonEqual(a, b interface{}) (bool, error) {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(aj, &av); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(bj, &bv); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
+
+// loadPolicies reads the policy objects of the YAML and JSON files of the
+// directories, the files are walked in lexical order and the objects which
+// aren't policies are ignored
+func loadPolicies(dirs []string) ([]*unstructured.Unstructured, error) {
+	var policies []*unstructured.Unstructured
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			switch filepath.Ext(path) {
+			case ".yaml", ".yml", ".json":
+			default:
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			nodes, err := kio.FromBytes(content)
+			if err != nil {
+				return fmt.Errorf("parsing policy file %s: %w", path, err)
+			}
+			objects, err := toUnstructured(nodes)
+			if err != nil {
+				return fmt.Errorf("parsing policy file %s: %w", path, err)
+			}
+			for _, u := range objects {
+				if isPolicyObject(u) {
+					policies = append(policies, u)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("loading policies from %s: %w", dir, err)
+		}
+	}
+	return policies, nil
+}
+
+// mergePolicies adds the policies to the objects, a policy of the objects
+// takes precedence over a policy with the same kind and name
+func mergePolicies(objects, policies []*unstructured.Unstructured) []*unstructured.Unstructured {
+	key := func(u *unstructured.Unstructured) string {
+		return u.GroupVersionKind().GroupKind().String() + "/" + u.GetNamespace() + "/" + u.GetName()
+	}
+	seen := map[string]bool{}
+	for _, u := range objects {
+		seen[key(u)] = true
+	}
+	merged := objects
+	for _, p := range policies {
+		if !seen[key(p)] {
+			seen[key(p)] = true
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestProcessWithPolicyDirs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "labels"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "labels", "policy.yaml"), []byte(celPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# policies"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := kio.FromBytes([]byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unowned
+  namespace: default
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fc := yaml.MustParse(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Gatekeeper
+metadata:
+  name: gatekeeper
+policyDirs:
+- ` + dir)
+
+	rl := &framework.ResourceList{Items: items, FunctionConfig: fc}
+	if err := (&GatekeeperProcessor{}).Process(rl); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rl.Items) != 1 {
+		t.Errorf("expected the policies not to be added to the items, got %d items", len(rl.Items))
+	}
+	expected := &framework.Result{
+		Items: []framework.ResultItem{
+			{
+				Message:     "missing required labels: owner\nviolatedConstraint: require-owner",
+				Severity:    framework.Warning,
+				ResourceRef: resourceRef("v1", "ConfigMap", "unowned", "default"),
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, rl.Result) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, rl.Result)
+	}
+
+	// a policy of the package takes precedence over the policy of the directory
+	inPackage, err := kio.FromBytes([]byte(`
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  parameters:
+    labels: []
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl = &framework.ResourceList{Items: append(items, inPackage...), FunctionConfig: fc}
+	if err := (&GatekeeperProcessor{}).Process(rl); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rl.Result != nil {
+		t.Errorf("expected no results, got:\n%+v", rl.Result)
+	}
+}
+
+func TestLoadPoliciesErrors(t *testing.T) {
+	if _, err := loadPolicies([]string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
@@ -15,14 +15,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
 
 	opatypes "github.com/open-policy-agent/frameworks/constraint/pkg/types"
-	// The gatekeeper/pkg/gator/test package is the underlying libraries for
-	// the `gator test` subcommand, not a library for testing golang code.
-	gatortest "github.com/open-policy-agent/gatekeeper/pkg/gator/test"
 	opautil "github.com/open-policy-agent/gatekeeper/pkg/util"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
@@ -30,22 +28,73 @@ import (
 	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// RemediationAnnotation is the annotation of a constraint or of a ValidatingAdmissionPolicy
+// whose value, e.g. a hint or a link to the docs, is added to the message of its violations
+const RemediationAnnotation = "gatekeeper.kpt.dev/remediation"
+
 // Validate makes sure the configs passed to it comply with any Constraints and
-// Constraint Templates present in the list of configs
-func Validate(objects []*unstructured.Unstructured) (*framework.Result, error) {
-	resps, err := gatortest.Test(objects)
+// Constraint Templates present in the list of configs, and with the
+// ValidatingAdmissionPolicies if they are enabled in the config. When the config
+// has a sync list, only the configs of its kinds are available in data.inventory.
+func Validate(objects []*unstructured.Unstructured, cfg *Gatekeeper) (*framework.Result, error) {
+	// the templates with the K8sNativeValidation engine and their constraints
+	// are evaluated with CEL, the others with OPA
+	templates, err := celTemplates(objects)
+	if err != nil {
+		return nil, err
+	}
+	celTemplateNames := map[string]bool{}
+	for _, t := range templates {
+		celTemplateNames[t.name] = true
+	}
+	var regoObjects []*unstructured.Unstructured
+	for _, u := range objects {
+		switch {
+		case isConstraintTemplate(u) && celTemplateNames[u.GetName()]:
+			continue
+		case isConstraint(u) && templates[u.GetKind()] != nil:
+			continue
+		}
+		regoObjects = append(regoObjects, u)
+	}
+	results, err := reviewRego(regoObjects, cfg.Sync)
+	if err != nil {
+		return nil, err
+	}
+
+	celResults, err := evaluateCELTemplates(templates, objects)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.ValidatingAdmissionPolicies {
+		vapResults, err := evaluateVAPs(objects)
+		if err != nil {
+			return nil, err
+		}
+		celResults = append(celResults, vapResults...)
+	}
 
-	results := resps.Results()
-	if len(results) > 0 {
-		return parseResults(results)
+	items, err := parseResults(results)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range celResults {
+		item, err := resultItem(r.message, r.object, r.enforcementAction)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
 	}
-	return nil, nil
+	if len(items) == 0 {
+		return nil, nil
+	}
+	sortResultItems(items)
+	return &framework.Result{
+		Items: items,
+	}, nil
 }
 
-func parseResults(results []*opatypes.Result) (*framework.Result, error) {
+func parseResults(results []*opatypes.Result) ([]framework.ResultItem, error) {
 	var items []framework.ResultItem
 
 	for _, r := range results {
@@ -54,51 +103,99 @@ func parseResults(results []*opatypes.Result) (*framework.Result, error) {
 			return nil, fmt.Errorf("could not cast to unstructured: %+v", r.Resource)
 		}
 
-		item := framework.ResultItem{
-			Message: fmt.Sprintf("%s\nviolatedConstraint: %s", r.Msg, r.Constraint.GetName()),
-			ResourceRef: yaml.ResourceIdentifier{
-				TypeMeta: yaml.TypeMeta{
-					APIVersion: u.GetAPIVersion(),
-					Kind:       u.GetKind(),
-				},
-				NameMeta: yaml.NameMeta{
-					Name:      u.GetName(),
-					Namespace: u.GetNamespace(),
-				},
-			},
+		item, err := resultItem(violationMessage(r.Msg, "violatedConstraint", r.Constraint), u, r.EnforcementAction)
+		if err != nil {
+			return nil, err
 		}
+		item.Field = detailsField(r.Metadata)
+		items = append(items, item)
+	}
+	return items, nil
+}
 
-		switch r.EnforcementAction {
-		case string(opautil.Dryrun):
-			item.Severity = framework.Info
-		case string(opautil.Warn):
-			item.Severity = framework.Warning
-		default:
-			item.Severity = framework.Error
-		}
+// violationMessage returns the message of a violation of the policy, followed by
+// the name of the policy and its remediation annotation if any
+func violationMessage(msg, violated string, policy *unstructured.Unstructured) string {
+	msg = fmt.Sprintf("%s\n%s: %s", msg, violated, policy.GetName())
+	if remediation := policy.GetAnnotations()[RemediationAnnotation]; remediation != "" {
+		msg = fmt.Sprintf("%s\nremediation: %s", msg, remediation)
+	}
+	return msg
+}
 
-		path, foundPath := u.GetAnnotations()[kioutil.PathAnnotation]
-		index, foundIndex := u.GetAnnotations()[kioutil.IndexAnnotation]
-		if foundPath {
-			item.File = framework.File{
-				Path: path,
+// detailsField returns the violating field of the details of a Rego violation,
+// the path of the field is read from the field or path key, and its current and
+// proposed values from the currentValue and proposedValue or suggestedValue keys
+func detailsField(metadata map[string]interface{}) framework.Field {
+	details, ok := metadata["details"].(map[string]interface{})
+	if !ok {
+		return framework.Field{}
+	}
+	value := func(keys ...string) string {
+		for _, key := range keys {
+			v, found := details[key]
+			if !found || v == nil {
+				continue
+			}
+			if s, ok := v.(string); ok {
+				return s
 			}
-			if foundIndex {
-				idx, err := strconv.Atoi(index)
-				if err != nil {
-					return nil, err
-				}
-				item.File.Index = idx
+			data, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Sprint(v)
 			}
+			return string(data)
 		}
+		return ""
+	}
+	return framework.Field{
+		Path:           value("field", "path"),
+		CurrentValue:   value("currentValue"),
+		SuggestedValue: value("proposedValue", "suggestedValue"),
+	}
+}
 
-		items = append(items, item)
+// resultItem returns the result of a violation by the object, the severity
+// depends on the enforcement action
+func resultItem(msg string, u *unstructured.Unstructured, enforcementAction string) (framework.ResultItem, error) {
+	item := framework.ResultItem{
+		Message: msg,
+		ResourceRef: yaml.ResourceIdentifier{
+			TypeMeta: yaml.TypeMeta{
+				APIVersion: u.GetAPIVersion(),
+				Kind:       u.GetKind(),
+			},
+			NameMeta: yaml.NameMeta{
+				Name:      u.GetName(),
+				Namespace: u.GetNamespace(),
+			},
+		},
 	}
-	sortResultItems(items)
 
-	return &framework.Result{
-		Items: items,
-	}, nil
+	switch enforcementAction {
+	case string(opautil.Dryrun):
+		item.Severity = framework.Info
+	case string(opautil.Warn):
+		item.Severity = framework.Warning
+	default:
+		item.Severity = framework.Error
+	}
+
+	path, foundPath := u.GetAnnotations()[kioutil.PathAnnotation]
+	index, foundIndex := u.GetAnnotations()[kioutil.IndexAnnotation]
+	if foundPath {
+		item.File = framework.File{
+			Path: path,
+		}
+		if foundIndex {
+			idx, err := strconv.Atoi(index)
+			if err != nil {
+				return item, err
+			}
+			item.File.Index = idx
+		}
+	}
+	return item, nil
 }
 
 // TODO(mengqiy): upstream this to the SDK
@@ -261,3 +261,66 @@ func TestSortResultItems(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDetails(t *testing.T) {
+	objects := parseObjects(t, `
+apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8smaxreplicas
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sMaxReplicas
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |-
+      package k8smaxreplicas
+      violation[{"msg": msg, "details": details}] {
+        replicas := input.review.object.spec.replicas
+        replicas > input.parameters.max
+        msg := sprintf("%v replicas are too many", [replicas])
+        details := {"field": "spec.replicas", "currentValue": replicas, "proposedValue": input.parameters.max}
+      }
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sMaxReplicas
+metadata:
+  name: max-replicas
+  annotations:
+    gatekeeper.kpt.dev/remediation: https://example.com/docs/replicas
+spec:
+  parameters:
+    max: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: default
+spec:
+  replicas: 5
+`)
+	result, err := Validate(objects, &Gatekeeper{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &framework.Result{
+		Items: []framework.ResultItem{
+			{
+				Message:     "5 replicas are too many\nviolatedConstraint: max-replicas\nremediation: https://example.com/docs/replicas",
+				Severity:    framework.Error,
+				ResourceRef: resourceRef("apps/v1", "Deployment", "backend", "default"),
+				Field: framework.Field{
+					Path:           "spec.replicas",
+					CurrentValue:   "5",
+					SuggestedValue: "3",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, result)
+	}
+}
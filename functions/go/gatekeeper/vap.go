@@ -0,0 +1,348 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	opautil "github.com/open-policy-agent/gatekeeper/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crdGroupKind is the group and kind of the CustomResourceDefinitions
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+const (
+	admissionRegistrationGroup = "admissionregistration.k8s.io"
+	vapKind                    = "ValidatingAdmissionPolicy"
+	vapBindingKind             = "ValidatingAdmissionPolicyBinding"
+)
+
+// resourceRule is a rule of the matchConstraints or matchResources of a policy
+type resourceRule struct {
+	APIGroups   []string `json:"apiGroups,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty"`
+	Resources   []string `json:"resources,omitempty"`
+	Operations  []string `json:"operations,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+}
+
+// matchResources selects the objects of a policy or of a binding
+type matchResources struct {
+	NamespaceSelector    *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	ObjectSelector       *metav1.LabelSelector `json:"objectSelector,omitempty"`
+	ResourceRules        []resourceRule        `json:"resourceRules,omitempty"`
+	ExcludeResourceRules []resourceRule        `json:"excludeResourceRules,omitempty"`
+}
+
+type vapSpec struct {
+	celSource        `json:",inline"`
+	ParamKind        *metav1.TypeMeta `json:"paramKind,omitempty"`
+	MatchConstraints *matchResources  `json:"matchConstraints,omitempty"`
+}
+
+type vapBindingSpec struct {
+	PolicyName string `json:"policyName"`
+	ParamRef   *struct {
+		Name                    string                `json:"name,omitempty"`
+		Namespace               string                `json:"namespace,omitempty"`
+		Selector                *metav1.LabelSelector `json:"selector,omitempty"`
+		ParameterNotFoundAction string                `json:"parameterNotFoundAction,omitempty"`
+	} `json:"paramRef,omitempty"`
+	MatchResources    *matchResources `json:"matchResources,omitempty"`
+	ValidationActions []string        `json:"validationActions,omitempty"`
+}
+
+func isValidatingAdmissionPolicy(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().Group == admissionRegistrationGroup && u.GetKind() == vapKind
+}
+
+func isValidatingAdmissionPolicyBinding(u *unstructured.Unstructured) bool {
+	return u.GroupVersionKind().Group == admissionRegistrationGroup && u.GetKind() == vapBindingKind
+}
+
+// resourceNames are the resources of the kinds defined by the CRDs of the package
+type resourceNames map[schema.GroupKind]string
+
+// crdResourceNames returns the resources of the kinds of the CRDs among the objects
+// from their spec.names.plural
+func crdResourceNames(objects []*unstructured.Unstructured) resourceNames {
+	names := resourceNames{}
+	for _, u := range objects {
+		if u.GroupVersionKind().GroupKind() != crdGroupKind {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(u.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(u.Object, "spec", "names", "kind")
+		plural, _, _ := unstructured.NestedString(u.Object, "spec", "names", "plural")
+		if kind != "" && plural != "" {
+			names[schema.GroupKind{Group: group, Kind: kind}] = plural
+		}
+	}
+	return names
+}
+
+// resourceName returns the resource of a kind, the plural of the CRD of the kind
+// if it is in the package, otherwise the resource is guessed from the kind since
+// there is no discovery at render time
+func (names resourceNames) resourceName(gvk schema.GroupVersionKind) string {
+	if plural, found := names[gvk.GroupKind()]; found {
+		return plural
+	}
+	return guessResourceName(gvk.Kind)
+}
+
+// guessResourceName guesses the resource of a kind with the English plural rules
+// like the default RESTMapper, e.g. ingresses for Ingress and networkpolicies for
+// NetworkPolicy. The irregular plurals of the kinds without a CRD in the package
+// are not known, e.g. a CRD with the plural cacti for Cactus.
+func guessResourceName(kind string) string {
+	name := strings.ToLower(kind)
+	switch {
+	case strings.HasSuffix(name, "endpoints"):
+		return name
+	case strings.HasSuffix(name, "s") || strings.HasSuffix(name, "x") ||
+		strings.HasSuffix(name, "ch") || strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case len(name) > 1 && strings.HasSuffix(name, "y") && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	default:
+		return name + "s"
+	}
+}
+
+func containsOrStar(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks if the rule matches the creation of the object
+func (r resourceRule) matches(u *unstructured.Unstructured, names resourceNames) bool {
+	gvk := u.GroupVersionKind()
+	scope := "Cluster"
+	if u.GetNamespace() != "" {
+		scope = "Namespaced"
+	}
+	return containsOrStar(r.APIGroups, gvk.Group) &&
+		containsOrStar(r.APIVersions, gvk.Version) &&
+		containsOrStar(r.Resources, names.resourceName(gvk)) &&
+		(len(r.Operations) == 0 || containsOrStar(r.Operations, "CREATE")) &&
+		(r.Scope == "" || r.Scope == "*" || r.Scope == scope)
+}
+
+func selectorMatches(selector *metav1.LabelSelector, set map[string]string) (bool, error) {
+	if selector == nil {
+		return true, nil
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return s.Matches(labels.Set(set)), nil
+}
+
+/*
+matches checks if the object is selected, like the API server:
+  - the matchConstraints of a policy require resourceRules, a policy
+    without resourceRules matches nothing
+  - a binding without matchResources, or without resourceRules, doesn't
+    constrain the objects matched by its policy
+*/
+func (m *matchResources) matches(u *unstructured.Unstructured, ns *corev1.Namespace, names resourceNames, rulesRequired bool) (bool, error) {
+	if m == nil {
+		return !rulesRequired, nil
+	}
+	if ok, err := selectorMatches(m.ObjectSelector, u.GetLabels()); !ok || err != nil {
+		return false, err
+	}
+	// like the API server, the namespaceSelector matches the labels of a
+	// Namespace itself and all the cluster-scoped objects
+	if ns != nil {
+		if ok, err := selectorMatches(m.NamespaceSelector, ns.Labels); !ok || err != nil {
+			return false, err
+		}
+	}
+	for _, r := range m.ExcludeResourceRules {
+		if r.matches(u, names) {
+			return false, nil
+		}
+	}
+	if len(m.ResourceRules) == 0 {
+		return !rulesRequired, nil
+	}
+	for _, r := range m.ResourceRules {
+		if r.matches(u, names) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// enforcementAction maps the validationActions of a binding to the most severe
+// enforcement action, so that the results get the same severities as the constraints
+func enforcementAction(validationActions []string) string {
+	action := ""
+	for _, a := range validationActions {
+		switch a {
+		case "Deny":
+			return string(opautil.Deny)
+		case "Warn":
+			action = string(opautil.Warn)
+		case "Audit":
+			if action == "" {
+				action = string(opautil.Dryrun)
+			}
+		}
+	}
+	if action == "" {
+		return string(opautil.Deny)
+	}
+	return action
+}
+
+// vapParams returns the params of the binding for the object, a nil slice
+// means that the binding has no params and the policy is evaluated once
+func vapParams(spec vapSpec, binding vapBindingSpec, u *unstructured.Unstructured, objects []*unstructured.Unstructured) ([]any, error) {
+	if spec.ParamKind == nil || binding.ParamRef == nil {
+		return nil, nil
+	}
+	namespace := binding.ParamRef.Namespace
+	if namespace == "" {
+		namespace = u.GetNamespace()
+	}
+	var params []any
+	for _, p := range objects {
+		if p.GetAPIVersion() != spec.ParamKind.APIVersion || p.GetKind() != spec.ParamKind.Kind {
+			continue
+		}
+		if p.GetNamespace() != "" && p.GetNamespace() != namespace {
+			continue
+		}
+		if binding.ParamRef.Name != "" && p.GetName() != binding.ParamRef.Name {
+			continue
+		}
+		ok, err := selectorMatches(binding.ParamRef.Selector, p.GetLabels())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			params = append(params, p.Object)
+		}
+	}
+	return params, nil
+}
+
+// evaluateVAPs evaluates the ValidatingAdmissionPolicies through their bindings
+// against the objects they match
+func evaluateVAPs(objects []*unstructured.Unstructured) ([]celResult, error) {
+	policies := map[string]*unstructured.Unstructured{}
+	for _, u := range objects {
+		if isValidatingAdmissionPolicy(u) {
+			policies[u.GetName()] = u
+		}
+	}
+
+	names := crdResourceNames(objects)
+	var results []celResult
+	for _, b := range objects {
+		if !isValidatingAdmissionPolicyBinding(b) {
+			continue
+		}
+		var binding vapBindingSpec
+		if err := decodeSpec(b, &binding); err != nil {
+			return nil, err
+		}
+		policy, found := policies[binding.PolicyName]
+		if !found {
+			results = append(results, celResult{
+				object:  b,
+				message: fmt.Sprintf("%s %q not found in the package", vapKind, binding.PolicyName),
+			})
+			continue
+		}
+		var spec vapSpec
+		if err := decodeSpec(policy, &spec); err != nil {
+			return nil, err
+		}
+		prg, err := compileCEL(spec.celSource)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", vapKind, policy.GetName(), err)
+		}
+		action := enforcementAction(binding.ValidationActions)
+
+		for _, u := range objects {
+			if isPolicyObject(u) {
+				continue
+			}
+			ns, err := namespaceOf(u, objects)
+			if err != nil {
+				return nil, err
+			}
+			matched, err := spec.MatchConstraints.matches(u, ns, names, true)
+			if err != nil {
+				return nil, fmt.Errorf("matching %s %q: %w", vapKind, policy.GetName(), err)
+			}
+			if matched {
+				matched, err = binding.MatchResources.matches(u, ns, names, false)
+				if err != nil {
+					return nil, fmt.Errorf("matching %s %q: %w", vapBindingKind, b.GetName(), err)
+				}
+			}
+			if !matched {
+				continue
+			}
+			input, err := celInput(u, ns)
+			if err != nil {
+				return nil, err
+			}
+			params, err := vapParams(spec, binding, u, objects)
+			if err != nil {
+				return nil, err
+			}
+
+			var messages []string
+			switch {
+			case params == nil && spec.ParamKind != nil && binding.ParamRef != nil:
+				if binding.ParamRef.ParameterNotFoundAction != "Allow" {
+					messages = append(messages, fmt.Sprintf("no params found for %s %q", vapBindingKind, b.GetName()))
+				}
+			case params == nil:
+				messages = prg.evaluate(input, nil)
+			default:
+				for _, p := range params {
+					input["params"] = p
+					messages = append(messages, prg.evaluate(input, nil)...)
+				}
+			}
+			for _, msg := range messages {
+				results = append(results, celResult{
+					object:            u,
+					message:           violationMessage(msg, "violatedPolicy", policy),
+					enforcementAction: action,
+				})
+			}
+		}
+	}
+	return results, nil
+}
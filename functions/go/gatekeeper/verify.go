@@ -0,0 +1,178 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing/fstest"
+
+	"github.com/open-policy-agent/gatekeeper/pkg/gator"
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// packageFS returns a file system of the files of the package, the nodes are
+// written to the file of their path annotation in the order of their index
+// annotation, and the nodes without a path annotation are ignored
+func packageFS(nodes []*yaml.RNode) (fs.FS, error) {
+	type document struct {
+		index   int
+		content string
+	}
+	files := map[string][]document{}
+	for _, node := range nodes {
+		p, i, err := kioutil.GetFileAnnotations(node)
+		if err != nil {
+			return nil, err
+		}
+		if p == "" {
+			continue
+		}
+		index := 0
+		if i != "" {
+			if index, err = strconv.Atoi(i); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation %q: %w", kioutil.IndexAnnotation, i, err)
+			}
+		}
+		node = node.Copy()
+		for _, a := range []string{kioutil.PathAnnotation, kioutil.IndexAnnotation} {
+			if err := node.PipeE(yaml.ClearAnnotation(a)); err != nil {
+				return nil, err
+			}
+		}
+		s, err := node.String()
+		if err != nil {
+			return nil, err
+		}
+		p = path.Clean(filepath.ToSlash(p))
+		files[p] = append(files[p], document{index: index, content: s})
+	}
+
+	fsys := fstest.MapFS{}
+	for p, docs := range files {
+		sort.SliceStable(docs, func(i, j int) bool { return docs[i].index < docs[j].index })
+		var contents []string
+		for _, d := range docs {
+			contents = append(contents, d.content)
+		}
+		fsys[p] = &fstest.MapFile{Data: []byte(strings.Join(contents, "---\n")), Mode: 0644}
+	}
+	return fsys, nil
+}
+
+/*
+Verify runs the gator Suites of the package and of the directories, like
+`gator verify`
+  - the paths of the Suites are relative to their file, the files of the package
+    are the files of the path annotations of the nodes
+  - the result of each test case is an info if it passes and an error otherwise
+  - a Suite or a test which can't be run has an error result
+*/
+func Verify(nodes []*yaml.RNode, dirs []string) ([]framework.ResultItem, error) {
+	fsys, err := packageFS(nodes)
+	if err != nil {
+		return nil, err
+	}
+	items, found, err := verifySuites(fsys, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		dirItems, dirFound, err := verifySuites(os.DirFS(dir), dir)
+		if err != nil {
+			return nil, fmt.Errorf("loading Suites from %s: %w", dir, err)
+		}
+		items = append(items, dirItems...)
+		found = found || dirFound
+	}
+	if !found {
+		items = append(items, framework.ResultItem{
+			Message:  "no Suites found",
+			Severity: framework.Warning,
+		})
+	}
+	return items, nil
+}
+
+// verifySuites runs the Suites of the file system, the paths of the Suites are
+// reported relative to dir, and returns whether any Suite was found
+func verifySuites(fsys fs.FS, dir string) ([]framework.ResultItem, bool, error) {
+	suites, err := gator.ReadSuites(fsys, ".", true)
+	if err != nil {
+		return nil, false, err
+	}
+	runner, err := gator.NewRunner(fsys, gator.NewOPAClient)
+	if err != nil {
+		return nil, false, err
+	}
+	filter, err := gator.NewFilter("")
+	if err != nil {
+		return nil, false, err
+	}
+
+	var items []framework.ResultItem
+	for _, s := range suites {
+		// the metadata of the Suites isn't decoded by gator
+		content, err := fs.ReadFile(fsys, s.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		node, err := yaml.Parse(string(content))
+		if err != nil {
+			return nil, false, err
+		}
+		meta, err := node.GetMeta()
+		if err != nil {
+			return nil, false, err
+		}
+		item := func(msg string, severity framework.Severity) framework.ResultItem {
+			return framework.ResultItem{
+				Message:     msg,
+				Severity:    severity,
+				ResourceRef: meta.GetIdentifier(),
+				File:        framework.File{Path: filepath.Join(dir, filepath.FromSlash(s.Path))},
+			}
+		}
+
+		result := runner.Run(context.Background(), filter, s)
+		if result.Error != nil {
+			items = append(items, item(fmt.Sprintf("running Suite: %v", result.Error), framework.Error))
+			continue
+		}
+		for _, t := range result.TestResults {
+			if t.Error != nil {
+				items = append(items, item(fmt.Sprintf("test %q failed: %v", t.Name, t.Error), framework.Error))
+				continue
+			}
+			for _, c := range t.CaseResults {
+				if c.Error != nil {
+					items = append(items, item(fmt.Sprintf("test %q case %q failed: %v", t.Name, c.Name, c.Error), framework.Error))
+					continue
+				}
+				items = append(items, item(fmt.Sprintf("test %q case %q passed", t.Name, c.Name), framework.Info))
+			}
+		}
+	}
+	return items, len(suites) > 0, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+var suiteFiles = map[string]string{
+	"policies/template.yaml": `apiVersion: templates.gatekeeper.sh/v1
+kind: ConstraintTemplate
+metadata:
+  name: k8sdisallowedlabels
+spec:
+  crd:
+    spec:
+      names:
+        kind: K8sDisallowedLabels
+  targets:
+  - target: admission.k8s.gatekeeper.sh
+    rego: |
+      package k8sdisallowedlabels
+
+      violation[{"msg": msg}] {
+        input.review.object.metadata.labels.debug
+        msg := "debug label is not allowed"
+      }
+`,
+	"policies/constraint.yaml": `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sDisallowedLabels
+metadata:
+  name: no-debug
+`,
+	"policies/tests/debug.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: debug
+  labels:
+    debug: "true"
+`,
+	"policies/tests/allowed.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: allowed
+`,
+	"policies/suite.yaml": `apiVersion: test.gatekeeper.sh/v1alpha1
+kind: Suite
+metadata:
+  name: disallowed-labels
+tests:
+- name: no-debug
+  template: template.yaml
+  constraint: constraint.yaml
+  cases:
+  - name: debug-label
+    object: tests/debug.yaml
+    assertions:
+    - violations: yes
+      message: debug label
+  - name: no-labels
+    object: tests/allowed.yaml
+    assertions:
+    - violations: no
+  - name: wrong-expectation
+    object: tests/allowed.yaml
+    assertions:
+    - violations: 1
+- name: missing-template
+  template: missing.yaml
+  constraint: constraint.yaml
+`,
+}
+
+func suiteItem(msg string, severity framework.Severity, path string) framework.ResultItem {
+	return framework.ResultItem{
+		Message:     msg,
+		Severity:    severity,
+		ResourceRef: resourceRef("test.gatekeeper.sh/v1alpha1", "Suite", "disallowed-labels", ""),
+		File:        framework.File{Path: path},
+	}
+}
+
+func suiteResult(path, missingTemplate string) []framework.ResultItem {
+	return []framework.ResultItem{
+		suiteItem(`test "no-debug" case "debug-label" passed`, framework.Info, path),
+		suiteItem(`test "no-debug" case "no-labels" passed`, framework.Info, path),
+		suiteItem(`test "no-debug" case "wrong-expectation" failed: unexpected number of violations: got 0 violations but want exactly 1: got messages []`, framework.Error, path),
+		suiteItem(`test "missing-template" failed: reading ConstraintTemplate from "policies/missing.yaml": open policies/missing.yaml: `+missingTemplate, framework.Error, path),
+	}
+}
+
+func TestProcessVerify(t *testing.T) {
+	var items []*yaml.RNode
+	for _, p := range []string{
+		"policies/constraint.yaml",
+		"policies/suite.yaml",
+		"policies/template.yaml",
+		"policies/tests/allowed.yaml",
+		"policies/tests/debug.yaml",
+	} {
+		nodes, err := kio.FromBytes([]byte(suiteFiles[p]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := kioutil.DefaultPathAndIndexAnnotation("", nodes); err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range nodes {
+			if err := n.PipeE(yaml.SetAnnotation(kioutil.PathAnnotation, p)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		items = append(items, nodes...)
+	}
+
+	dir := t.TempDir()
+	for p, content := range suiteFiles {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testcases := []struct {
+		name     string
+		items    []*yaml.RNode
+		config   string
+		expected *framework.Result
+	}{
+		{
+			name:     "Suites of the package",
+			items:    items,
+			expected: &framework.Result{Items: suiteResult("policies/suite.yaml", "file does not exist")},
+		},
+		{
+			name:     "Suites of a directory",
+			config:   "suiteDirs:\n- " + dir,
+			expected: &framework.Result{Items: suiteResult(filepath.Join(dir, "policies/suite.yaml"), "no such file or directory")},
+		},
+		{
+			name: "no Suites",
+			expected: &framework.Result{
+				Items: []framework.ResultItem{
+					{
+						Message:  "no Suites found",
+						Severity: framework.Warning,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			fc := yaml.MustParse(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Gatekeeper
+metadata:
+  name: gatekeeper
+mode: verify
+` + tc.config)
+			rl := &framework.ResourceList{Items: tc.items, FunctionConfig: fc}
+			_ = (&GatekeeperProcessor{}).Process(rl)
+			if !reflect.DeepEqual(tc.expected, rl.Result) {
+				t.Errorf("expected:\n%+v\ngot:\n%+v", tc.expected, rl.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/kptdev/krm-functions-catalog/functions/go/internal
+
+go 1.24.10
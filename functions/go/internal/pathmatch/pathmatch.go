@@ -0,0 +1,100 @@
+// Package pathmatch matches the field paths of resources with the path
+// expressions of the functions, e.g. the by-path of search-replace and the
+// fieldPaths of create-setters. The field path elements are separated by
+// Delimiter, * matches any element, ** matches 0 or more elements and a[*]
+// matches any element of the list a.
+package pathmatch
+
+import (
+	"strings"
+)
+
+// Delimiter is the delimiter of the field path elements
+const Delimiter = "."
+
+// Match checks if the field path matches the path expression, the elements
+// of both are separated by Delimiter
+func Match(fieldPath, pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	patternElems := strings.Split(pattern, Delimiter)
+	fieldPathElems := strings.Split(strings.TrimPrefix(fieldPath, Delimiter), Delimiter)
+	return MatchElems(fieldPathElems, patternElems, func(i int, pattern string) bool {
+		return ElementMatch(fieldPathElems[i], pattern)
+	})
+}
+
+// MatchElems matches the fieldPathElems with the patternElems, * matches any
+// element, ** matches 0 or more elements, other elements are matched by
+// elemMatch with the index of the fieldPath element
+func MatchElems(fieldPathElems, patternElems []string, elemMatch func(i int, pattern string) bool) bool {
+	// this is a dynamic programming problem
+	// aim is to check if path array matches pattern array as per above rules
+	fieldPathElemsLen, patternElemsLen := len(fieldPathElems), len(patternElems)
+
+	// initialize a 2d boolean matrix to memorize results
+	// dp[i][j] stores the result, if fieldPath subarray of length i matches
+	// pattern subarray of length j
+	dp := make([][]bool, fieldPathElemsLen+1)
+	for i := range dp {
+		dp[i] = make([]bool, patternElemsLen+1)
+	}
+	dp[0][0] = true
+
+	// edge case 1: when pattern is empty, fieldPath of length grater than 0 doesn't match
+	for i := 1; i < fieldPathElemsLen+1; i++ {
+		dp[i][0] = false
+	}
+
+	// edge case 2: if fieldPath is empty, carry forward the previous result if the pattern element
+	// is `**` as it matches 0 or more elements.
+	for j := 1; j < patternElemsLen+1; j++ {
+		if patternElems[j-1] == "**" {
+			dp[0][j] = dp[0][j-1]
+		}
+	}
+
+	// fill rest of the matrix
+	for i := 1; i < fieldPathElemsLen+1; i++ {
+		for j := 1; j < patternElemsLen+1; j++ {
+			if patternElems[j-1] == "**" {
+				// `**` matches multiple elements, so carry forward the result from immediate
+				// neighbors, dp[i-1][j] match empty, dp[i][j-1] match multiple elements
+				dp[i][j] = dp[i][j-1] || dp[i-1][j]
+			} else if patternElems[j-1] == "*" || elemMatch(i-1, patternElems[j-1]) {
+				// if there is element match or `*` then get the result from previous diagonal element
+				dp[i][j] = dp[i-1][j-1]
+			}
+		}
+	}
+
+	/*Example matrix for fieldPath = [a,a,b,c,e,b] and pattern [a,*,b,**,b]
+		  a	a	b	c	e	b
+		a	T	F	F	F	F	F
+	  * F	T	F	F	F	F
+		b	F	F	T	F	F	F
+	 ** F	F	T	T	T	T
+		b	F	F	F	F	F	T
+	*/
+
+	return dp[fieldPathElemsLen][patternElemsLen]
+}
+
+// ElementMatch matches single element with pattern for single element
+func ElementMatch(elem, pattern string) bool {
+	// scalar field case `metadata` matches `metadata`
+	if elem == pattern {
+		return true
+	}
+	// array element e.g. a[*], *[*] and *[b] matches a[b]
+	if strings.Contains(elem, "[") {
+		elemParts := strings.Split(elem, "[")
+		patternParts := strings.Split(pattern, "[")
+		if patternParts[0] != "*" && elemParts[0] != patternParts[0] {
+			return false
+		}
+		return len(patternParts) > 1 && (patternParts[1] == "*]" || elemParts[1] == patternParts[1])
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package pathmatch
+
+import (
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	var tests = []struct {
+		fieldPath string
+		pattern   string
+		expected  bool
+	}{
+		{fieldPath: "metadata.name", pattern: "metadata.name", expected: true},
+		{fieldPath: ".metadata.name", pattern: "metadata.name", expected: true},
+		{fieldPath: "metadata.name", pattern: "metadata.namespace", expected: false},
+		{fieldPath: "metadata.name", pattern: "", expected: false},
+		{fieldPath: "metadata.name", pattern: "*.name", expected: true},
+		{fieldPath: "metadata.name", pattern: "**.name", expected: true},
+		{fieldPath: "spec.template.spec.containers[0].image", pattern: "spec.**.image", expected: true},
+		{fieldPath: "spec.template.spec.containers[0].image", pattern: "spec.*.image", expected: false},
+		{fieldPath: "spec.containers[0].image", pattern: "spec.containers[*].image", expected: true},
+		{fieldPath: "spec.containers[0].image", pattern: "spec.*[0].image", expected: true},
+		{fieldPath: "spec.containers[0].image", pattern: "spec.containers[1].image", expected: false},
+		{fieldPath: "spec.containers[0].image", pattern: "spec.containers.image", expected: false},
+		{fieldPath: "a.a.b.c.e.b", pattern: "a.*.b.**.b", expected: true},
+	}
+	for _, test := range tests {
+		if actual := Match(test.fieldPath, test.pattern); actual != test.expected {
+			t.Errorf("Match(%q, %q) = %t, expected %t", test.fieldPath, test.pattern, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// openAPIRefPrefix is the prefix of the references to the schemas of an OpenAPI v3 document
+const openAPIRefPrefix = "#/components/schemas/"
+
+// bundleOptions are the options of the schema-bundle subcommand
+type bundleOptions struct {
+	crdPaths          []string
+	openAPIPaths      []string
+	output            string
+	kubernetesVersion string
+}
+
+func newSchemaBundleCommand() *cobra.Command {
+	o := &bundleOptions{}
+	cmd := &cobra.Command{
+		Use:   "schema-bundle",
+		Short: "Build a schema directory from CRDs and Kubernetes OpenAPI v3 documents",
+		Long: `Build a schema directory from CRDs and Kubernetes OpenAPI v3 documents.
+
+The schemas are written with the layout of the kubernetes-json-schema repository,
+e.g. master-standalone/deployment-apps-v1.json and master-standalone-strict/deployment-apps-v1.json,
+so that the directory can be used as a schema location or baked into the function image.
+The schemas of the CRDs take precedence over the schemas of the OpenAPI documents.
+The kinds of two groups with the same first segment, e.g. example.com and example.org,
+would have the same file name, which is an error.`,
+		Example: `  # build the schemas of the CRDs of a directory and of a dump of kubectl get --raw /openapi/v3/apis/apps/v1
+  function schema-bundle --crds ./crds --openapi apps-v1.json --output ./schemas`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run()
+		},
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringSliceVar(&o.crdPaths, "crds", nil, "CRD YAML files or directories of CRD YAML files")
+	cmd.Flags().StringSliceVar(&o.openAPIPaths, "openapi", nil, "Kubernetes OpenAPI v3 documents in JSON or YAML")
+	cmd.Flags().StringVar(&o.output, "output", "", "the schema directory to write")
+	cmd.Flags().StringVar(&o.kubernetesVersion, "kubernetes-version", DefaultKubernetesVersion, "the Kubernetes version of the schemas")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+// bundleSchema is the OpenAPI v3 schema of a kind and apiVersion
+type bundleSchema struct {
+	kind       string
+	apiVersion string
+	schema     map[string]any
+}
+
+func (o *bundleOptions) run() error {
+	if len(o.crdPaths) == 0 && len(o.openAPIPaths) == 0 {
+		return fmt.Errorf("at least one of --crds or --openapi must be provided")
+	}
+	if _, err := parseKubeVersion(o.kubernetesVersion); err != nil {
+		return err
+	}
+
+	// the schemas of the CRDs are added last so that they overwrite
+	// the schemas of the OpenAPI documents
+	var schemas []bundleSchema
+	for _, path := range o.openAPIPaths {
+		s, err := readOpenAPISchemas(path)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, s...)
+	}
+	crdFiles, err := yamlFiles(o.crdPaths)
+	if err != nil {
+		return err
+	}
+	for _, path := range crdFiles {
+		s, err := readCRDSchemas(path)
+		if err != nil {
+			return err
+		}
+		schemas = append(schemas, s...)
+	}
+
+	// the file names only keep the first segment of the group, e.g. the schemas of
+	// Widget of example.com/v1 and example.org/v1 would overwrite each other
+	apiVersions := map[string]string{}
+	for _, s := range schemas {
+		path := schemaFile(o.output, o.kubernetesVersion, false, s.kind, s.apiVersion)
+		if other, found := apiVersions[path]; found && other != s.apiVersion {
+			return fmt.Errorf("the schemas of %s %s and %s %s have the same file name %s, the file names only keep the first segment of the group",
+				other, s.kind, s.apiVersion, s.kind, filepath.Base(path))
+		}
+		apiVersions[path] = s.apiVersion
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, s := range schemas {
+			path := schemaFile(o.output, o.kubernetesVersion, strict, s.kind, s.apiVersion)
+			if err := writeSchema(path, openAPIToJSONSchema(s.schema, strict)); err != nil {
+				return fmt.Errorf("failed to write schema of %s %s: %w", s.apiVersion, s.kind, err)
+			}
+		}
+	}
+	return nil
+}
+
+// schemaFile returns the file of the schema of the kind and apiVersion in the layout of the
+// kubernetes-json-schema repository e.g. master-standalone-strict/deployment-apps-v1.json
+func schemaFile(dir, kubernetesVersion string, strict bool, kind, apiVersion string) string {
+	if kubernetesVersion != DefaultKubernetesVersion && !strings.HasPrefix(kubernetesVersion, "v") {
+		kubernetesVersion = "v" + kubernetesVersion
+	}
+	standalone := kubernetesVersion + "-standalone"
+	if strict {
+		standalone += "-strict"
+	}
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found {
+		group, version = "", apiVersion
+	}
+	name := strings.ToLower(kind)
+	if group != "" {
+		name += "-" + strings.ToLower(strings.Split(group, ".")[0])
+	}
+	name += "-" + strings.ToLower(version) + ".json"
+	return filepath.Join(dir, standalone, name)
+}
+
+// yamlFiles returns the YAML and JSON files of the paths, the files
+// of the directories are walked in lexical order
+func yamlFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			switch filepath.Ext(p) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readCRDSchemas returns the schemas of the served versions of the CRDs of the file
+func readCRDSchemas(path string) ([]bundleSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := fn.ParseKubeObjects(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var schemas []bundleSchema
+	for _, obj := range objs {
+		if !isCRD(obj) {
+			continue
+		}
+		crds, result := crdSchemas(obj)
+		if result != nil {
+			return nil, fmt.Errorf("%s: CRD %q: %s", path, obj.GetName(), result.Message)
+		}
+		for _, c := range crds {
+			schemas = append(schemas, bundleSchema{kind: c.kind, apiVersion: c.apiVersion, schema: c.schema})
+		}
+	}
+	return schemas, nil
+}
+
+/*
+readOpenAPISchemas returns the schemas of the kinds of the Kubernetes OpenAPI v3 document
+e.g. the output of kubectl get --raw /openapi/v3/apis/apps/v1, the kinds are the schemas
+with x-kubernetes-group-version-kind and the references to the other schemas of the
+document are inlined so that each schema is standalone
+*/
+func readOpenAPISchemas(path string) ([]bundleSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	defs := doc.Components.Schemas
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("%s is not an OpenAPI v3 document, it has no components.schemas", path)
+	}
+
+	var schemas []bundleSchema
+	for _, name := range sortedKeys(defs) {
+		gvks, _ := defs[name]["x-kubernetes-group-version-kind"].([]any)
+		for _, gvk := range gvks {
+			m, _ := gvk.(map[string]any)
+			group, _ := m["group"].(string)
+			version, _ := m["version"].(string)
+			kind, _ := m["kind"].(string)
+			if version == "" || kind == "" {
+				continue
+			}
+			apiVersion := version
+			if group != "" {
+				apiVersion = group + "/" + version
+			}
+			schema, err := inlineRefs(defs[name], defs, map[string]bool{name: true})
+			if err != nil {
+				return nil, fmt.Errorf("%s: schema %s: %w", path, name, err)
+			}
+			schemas = append(schemas, bundleSchema{kind: kind, apiVersion: apiVersion, schema: schema.(map[string]any)})
+		}
+	}
+	return schemas, nil
+}
+
+// inlineRefs replaces the references of the schema with the referenced schemas,
+// a recursive reference e.g. in JSONSchemaProps is replaced with an empty schema
+// which allows any value
+func inlineRefs(schema any, defs map[string]map[string]any, seen map[string]bool) (any, error) {
+	switch val := schema.(type) {
+	case map[string]any:
+		if ref, ok := val["$ref"].(string); ok {
+			name := strings.TrimPrefix(ref, openAPIRefPrefix)
+			def, found := defs[name]
+			if !found || name == ref {
+				return nil, fmt.Errorf("unresolved reference %q", ref)
+			}
+			if seen[name] {
+				return map[string]any{}, nil
+			}
+			seen[name] = true
+			defer delete(seen, name)
+			return inlineRefs(def, defs, seen)
+		}
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			v, err := inlineRefs(item, defs, seen)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			v, err := inlineRefs(item, defs, seen)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	default:
+		return schema, nil
+	}
+}
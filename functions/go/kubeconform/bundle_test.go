@@ -0,0 +1,265 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+const widgetCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: string
+`
+
+const appsOpenAPI = `{
+  "openapi": "3.0.0",
+  "components": {
+    "schemas": {
+      "io.k8s.api.apps.v1.Deployment": {
+        "type": "object",
+        "properties": {
+          "apiVersion": {"type": "string"},
+          "kind": {"type": "string"},
+          "metadata": {"allOf": [{"$ref": "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}]},
+          "spec": {
+            "type": "object",
+            "properties": {
+              "replicas": {"type": "integer"},
+              "extra": {"$ref": "#/components/schemas/io.k8s.apiextensions.v1.JSONSchemaProps"}
+            }
+          }
+        },
+        "x-kubernetes-group-version-kind": [{"group": "apps", "kind": "Deployment", "version": "v1"}]
+      },
+      "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"}
+        }
+      },
+      "io.k8s.apiextensions.v1.JSONSchemaProps": {
+        "type": "object",
+        "properties": {
+          "items": {"$ref": "#/components/schemas/io.k8s.apiextensions.v1.JSONSchemaProps"}
+        }
+      }
+    }
+  }
+}`
+
+func TestSchemaBundle(t *testing.T) {
+	in := t.TempDir()
+	crdDir := filepath.Join(in, "crds")
+	assert.NoError(t, os.MkdirAll(crdDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(crdDir, "widget.yaml"), []byte(widgetCRD), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(crdDir, "README.md"), []byte("not a CRD"), 0600))
+	openAPI := filepath.Join(in, "apps-v1.json")
+	assert.NoError(t, os.WriteFile(openAPI, []byte(appsOpenAPI), 0600))
+
+	out := filepath.Join(t.TempDir(), "schemas")
+	o := &bundleOptions{
+		crdPaths:          []string{crdDir},
+		openAPIPaths:      []string{openAPI},
+		output:            out,
+		kubernetesVersion: "1.29",
+	}
+	if !assert.NoError(t, o.run()) {
+		t.FailNow()
+	}
+	for _, name := range []string{
+		"v1.29-standalone/deployment-apps-v1.json",
+		"v1.29-standalone/widget-example-v1.json",
+		"v1.29-standalone-strict/deployment-apps-v1.json",
+		"v1.29-standalone-strict/widget-example-v1.json",
+	} {
+		assert.FileExists(t, filepath.Join(out, name))
+	}
+
+	var items []*fn.KubeObject
+	for _, yamlStr := range []string{`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: 5
+spec:
+  replicas: 3
+  extra:
+    items:
+      items: {}
+`, `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+spec:
+  size: large
+  color: red
+`} {
+		obj, err := fn.ParseKubeObject([]byte(yamlStr))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+	fc, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Kubeconform
+metadata:
+  name: kubeconform
+schemaLocations:
+- %s
+strict: true
+kubernetesVersion: "1.29"
+`, out)))
+	assert.NoError(t, err)
+	rl := &fn.ResourceList{FunctionConfig: fc, Items: items}
+	isValid, err := Run(rl)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+	expected := []string{
+		"[error] apps/v1/Deployment metadata: 'allOf' failed",
+		"[error] example.com/v1/Widget/widget spec: additional properties 'color' not allowed",
+		"[info]: summary: Deployment: 0 valid, 1 invalid; Widget: 0 valid, 1 invalid",
+	}
+	if !assert.Len(t, rl.Results, len(expected)) {
+		t.FailNow()
+	}
+	for i, result := range rl.Results {
+		assert.Contains(t, result.String(), expected[i])
+	}
+}
+
+func TestSchemaBundleErrors(t *testing.T) {
+	dir := t.TempDir()
+	notOpenAPI := filepath.Join(dir, "not-openapi.json")
+	assert.NoError(t, os.WriteFile(notOpenAPI, []byte(`{"swagger": "2.0"}`), 0600))
+	unresolved := filepath.Join(dir, "unresolved.json")
+	assert.NoError(t, os.WriteFile(unresolved, []byte(`{"components": {"schemas": {"Foo": {
+  "properties": {"bar": {"$ref": "#/components/schemas/Bar"}},
+  "x-kubernetes-group-version-kind": [{"group": "", "kind": "Foo", "version": "v1"}]
+}}}}`), 0600))
+
+	crdDir := filepath.Join(dir, "crds")
+	assert.NoError(t, os.MkdirAll(crdDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(crdDir, "widget-com.yaml"), []byte(widgetCRD), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(crdDir, "widget-org.yaml"),
+		[]byte(strings.ReplaceAll(widgetCRD, "example.com", "example.org")), 0600))
+
+	tests := []struct {
+		name   string
+		opts   bundleOptions
+		errMsg string
+	}{
+		{
+			name:   "groups with the same first segment",
+			opts:   bundleOptions{crdPaths: []string{crdDir}, output: dir, kubernetesVersion: DefaultKubernetesVersion},
+			errMsg: "the schemas of example.com/v1 Widget and example.org/v1 Widget have the same file name widget-example-v1.json",
+		},
+		{
+			name:   "no inputs",
+			opts:   bundleOptions{output: dir, kubernetesVersion: DefaultKubernetesVersion},
+			errMsg: "at least one of --crds or --openapi must be provided",
+		},
+		{
+			name:   "not an OpenAPI v3 document",
+			opts:   bundleOptions{openAPIPaths: []string{notOpenAPI}, output: dir, kubernetesVersion: DefaultKubernetesVersion},
+			errMsg: "not-openapi.json is not an OpenAPI v3 document, it has no components.schemas",
+		},
+		{
+			name:   "unresolved reference",
+			opts:   bundleOptions{openAPIPaths: []string{unresolved}, output: dir, kubernetesVersion: DefaultKubernetesVersion},
+			errMsg: `schema Foo: unresolved reference "#/components/schemas/Bar"`,
+		},
+		{
+			name:   "invalid Kubernetes version",
+			opts:   bundleOptions{openAPIPaths: []string{unresolved}, output: dir, kubernetesVersion: "latest"},
+			errMsg: `invalid Kubernetes version "latest"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.run()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestSchemaFile(t *testing.T) {
+	tests := []struct {
+		kind              string
+		apiVersion        string
+		strict            bool
+		kubernetesVersion string
+		expected          string
+	}{
+		{
+			kind:              "Service",
+			apiVersion:        "v1",
+			kubernetesVersion: DefaultKubernetesVersion,
+			expected:          "/schemas/master-standalone/service-v1.json",
+		},
+		{
+			kind:              "Deployment",
+			apiVersion:        "apps/v1",
+			strict:            true,
+			kubernetesVersion: DefaultKubernetesVersion,
+			expected:          "/schemas/master-standalone-strict/deployment-apps-v1.json",
+		},
+		{
+			kind:              "Ingress",
+			apiVersion:        "networking.k8s.io/v1",
+			kubernetesVersion: DefaultKubernetesVersion,
+			expected:          "/schemas/master-standalone/ingress-networking-v1.json",
+		},
+		{
+			kind:              "Pod",
+			apiVersion:        "v1",
+			kubernetesVersion: "1.29.3",
+			expected:          "/schemas/v1.29.3-standalone/pod-v1.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.apiVersion+"/"+tt.kind, func(t *testing.T) {
+			assert.Equal(t, filepath.FromSlash(tt.expected), schemaFile("/schemas", tt.kubernetesVersion, tt.strict, tt.kind, tt.apiVersion))
+		})
+	}
+}
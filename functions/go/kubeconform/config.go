@@ -0,0 +1,161 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	FnConfigGroup   = "fn.kpt.dev"
+	FnConfigVersion = "v1alpha1"
+	FnConfigKind    = "Kubeconform"
+)
+
+/*
+Kubeconform is the typed function config e.g.
+
+	apiVersion: fn.kpt.dev/v1alpha1
+	kind: Kubeconform
+	metadata:
+	  name: kubeconform
+	schemaLocations:
+	- file:///schemas
+	- https://example.com/schemas/{{ .ResourceKind }}.json
+	skipKinds:
+	- Kptfile
+	rejectKinds:
+	- v1/Secret
+	severityOverrides:
+	  ConfigMap: warning
+	missingSchemasAsWarnings: true
+	strict: true
+	kubernetesVersion: "1.29"
+*/
+type Kubeconform struct {
+	// SchemaLocations are the schema locations in order of precedence
+	SchemaLocations []string `json:"schemaLocations,omitempty" yaml:"schemaLocations,omitempty"`
+	// SkipKinds are the kinds which are not validated
+	SkipKinds []string `json:"skipKinds,omitempty" yaml:"skipKinds,omitempty"`
+	// RejectKinds are the kinds which are not allowed in the package
+	RejectKinds []string `json:"rejectKinds,omitempty" yaml:"rejectKinds,omitempty"`
+	// SeverityOverrides are the severities of the schema validation errors of the resources of a kind
+	SeverityOverrides map[string]fn.Severity `json:"severityOverrides,omitempty" yaml:"severityOverrides,omitempty"`
+	// IgnoreMissingSchemas skips the resources without a schema
+	IgnoreMissingSchemas bool `json:"ignoreMissingSchemas,omitempty" yaml:"ignoreMissingSchemas,omitempty"`
+	// MissingSchemasAsWarnings reports the resources without a schema as warnings
+	MissingSchemasAsWarnings bool `json:"missingSchemasAsWarnings,omitempty" yaml:"missingSchemasAsWarnings,omitempty"`
+	// Strict disallows the properties which are not in the schemas
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+	// KubernetesVersion is the Kubernetes version of the target cluster
+	KubernetesVersion string `json:"kubernetesVersion,omitempty" yaml:"kubernetesVersion,omitempty"`
+}
+
+// isKubeconform checks if the function config is the typed Kubeconform config
+func isKubeconform(fc *fn.KubeObject) bool {
+	return fc != nil && fc.IsGroupVersionKind(schema.GroupVersionKind{
+		Group:   FnConfigGroup,
+		Version: FnConfigVersion,
+		Kind:    FnConfigKind,
+	})
+}
+
+// getConfig reads the function config which is either a Kubeconform or a ConfigMap
+func getConfig(fc *fn.KubeObject) (KubeconformConfig, error) {
+	if !isKubeconform(fc) {
+		return extractConfig(fc), nil
+	}
+	var k Kubeconform
+	if err := fc.As(&k); err != nil {
+		return KubeconformConfig{}, fmt.Errorf("failed to read %s: %w", FnConfigKind, err)
+	}
+	for _, kind := range sortedKeys(k.SeverityOverrides) {
+		switch k.SeverityOverrides[kind] {
+		case fn.Error, fn.Warning, fn.Info:
+		default:
+			return KubeconformConfig{}, fmt.Errorf("invalid severity %q for kind %q in severityOverrides, must be one of [%s, %s, %s]",
+				k.SeverityOverrides[kind], kind, fn.Error, fn.Warning, fn.Info)
+		}
+	}
+	cfg := KubeconformConfig{
+		IgnoreMissingSchemas:     k.IgnoreMissingSchemas,
+		MissingSchemasAsWarnings: k.MissingSchemasAsWarnings,
+		SkipKinds:                k.SkipKinds,
+		RejectKinds:              k.RejectKinds,
+		SeverityOverrides:        k.SeverityOverrides,
+		Strict:                   k.Strict,
+		KubernetesVersion:        k.KubernetesVersion,
+	}
+	if len(k.SchemaLocations) > 0 {
+		cfg.SchemaLocation = k.SchemaLocations[0]
+		cfg.AdditionalSchemaLocations = k.SchemaLocations[1:]
+	}
+	return cfg, nil
+}
+
+// matchKind checks if the kind of the object, or its apiVersion and kind
+// e.g. apps/v1/Deployment, is one of the kinds
+func matchKind(obj *fn.KubeObject, kinds []string) bool {
+	for _, kind := range kinds {
+		if kind == obj.GetKind() || kind == obj.GetAPIVersion()+"/"+obj.GetKind() {
+			return true
+		}
+	}
+	return false
+}
+
+// severityOverride returns the severity of the results of the object, the apiVersion
+// and kind e.g. apps/v1/Deployment takes precedence over the kind
+func severityOverride(obj *fn.KubeObject, overrides map[string]fn.Severity) (fn.Severity, bool) {
+	if s, ok := overrides[obj.GetAPIVersion()+"/"+obj.GetKind()]; ok {
+		return s, true
+	}
+	s, ok := overrides[obj.GetKind()]
+	return s, ok
+}
+
+// kindCount is the number of valid and invalid resources of a kind
+type kindCount struct {
+	valid, invalid int
+}
+
+// summaryResult returns the info result with the number of valid and
+// invalid resources per kind e.g. Deployment: 2 valid, 1 invalid
+func summaryResult(counts map[string]*kindCount) *fn.Result {
+	msg := "summary:"
+	if len(counts) == 0 {
+		msg += " no resources validated"
+	}
+	for i, kind := range sortedKeys(counts) {
+		if i > 0 {
+			msg += ";"
+		}
+		msg += fmt.Sprintf(" %s: %d valid, %d invalid", kind, counts[kind].valid, counts[kind].invalid)
+	}
+	return fn.GeneralResult(msg, fn.Info)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
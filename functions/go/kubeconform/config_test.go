@@ -0,0 +1,187 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		yamlData string
+		expected KubeconformConfig
+		errMsg   string
+	}{
+		{
+			name: "typed config",
+			yamlData: `apiVersion: fn.kpt.dev/v1alpha1
+kind: Kubeconform
+metadata:
+  name: kubeconform
+schemaLocations:
+- /schemas
+- https://example.com/schemas
+skipKinds:
+- Kptfile
+rejectKinds:
+- v1/Secret
+severityOverrides:
+  ConfigMap: warning
+missingSchemasAsWarnings: true
+strict: true
+kubernetesVersion: "1.29"
+`,
+			expected: KubeconformConfig{
+				SchemaLocation:            "/schemas",
+				AdditionalSchemaLocations: []string{"https://example.com/schemas"},
+				MissingSchemasAsWarnings:  true,
+				SkipKinds:                 []string{"Kptfile"},
+				RejectKinds:               []string{"v1/Secret"},
+				SeverityOverrides:         map[string]fn.Severity{"ConfigMap": fn.Warning},
+				Strict:                    true,
+				KubernetesVersion:         "1.29",
+			},
+		},
+		{
+			name: "config map",
+			yamlData: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  schema_location: /schemas
+  skip_kinds: Kptfile
+`,
+			expected: KubeconformConfig{
+				SchemaLocation: "/schemas",
+				SkipKinds:      []string{"Kptfile"},
+			},
+		},
+		{
+			name: "invalid severity",
+			yamlData: `apiVersion: fn.kpt.dev/v1alpha1
+kind: Kubeconform
+metadata:
+  name: kubeconform
+severityOverrides:
+  ConfigMap: fatal
+`,
+			errMsg: `invalid severity "fatal" for kind "ConfigMap" in severityOverrides, must be one of [error, warning, info]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := fn.ParseKubeObject([]byte(tt.yamlData))
+			assert.NoError(t, err)
+			cfg, err := getConfig(fc)
+			if tt.errMsg != "" {
+				if assert.Error(t, err) {
+					assert.Equal(t, tt.errMsg, err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestRunWithKubeconform(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"v1.29-standalone/pod-v1.json":       podSchema,
+		"v1.29-standalone/configmap-v1.json": podSchema,
+	})
+
+	var items []*fn.KubeObject
+	for _, yamlStr := range []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+`, `
+apiVersion: v1
+kind: ConfigMap
+`, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: secret
+`, `
+apiVersion: example.com/v1
+kind: MyCustom
+metadata:
+  name: custom
+`, `
+apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: pkg
+`, `
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: ingress
+`} {
+		obj, err := fn.ParseKubeObject([]byte(yamlStr))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+
+	fc, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: fn.kpt.dev/v1alpha1
+kind: Kubeconform
+metadata:
+  name: kubeconform
+schemaLocations:
+- %s
+skipKinds:
+- Kptfile
+rejectKinds:
+- v1/Secret
+severityOverrides:
+  ConfigMap: warning
+  Secret: info
+  Ingress: warning
+missingSchemasAsWarnings: true
+kubernetesVersion: "1.29"
+`, dir)))
+	assert.NoError(t, err)
+
+	rl := &fn.ResourceList{
+		FunctionConfig: fc,
+		Items:          items,
+	}
+	isValid, err := Run(rl)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+	expected := []string{
+		"[warning] v1/ConfigMap : missing property 'metadata'",
+		"[error] v1/Secret/secret kind: v1 Secret is not allowed",
+		"[warning] example.com/v1/MyCustom/custom kind: could not find schema for example.com/v1 MyCustom",
+		"[error] extensions/v1beta1/Ingress/ingress apiVersion: extensions/v1beta1 Ingress was removed in Kubernetes v1.22",
+		"[info]: summary: ConfigMap: 0 valid, 1 invalid; Ingress: 0 valid, 1 invalid; MyCustom: 1 valid, 0 invalid; Pod: 1 valid, 0 invalid; Secret: 0 valid, 1 invalid",
+	}
+	if !assert.Len(t, rl.Results, len(expected)) {
+		t.FailNow()
+	}
+	for i, result := range rl.Results {
+		assert.Contains(t, result.String(), expected[i])
+	}
+}
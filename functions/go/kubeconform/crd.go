@@ -0,0 +1,280 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const (
+	CRDKind  = "CustomResourceDefinition"
+	CRDGroup = "apiextensions.k8s.io"
+)
+
+// crdVersion is a served version of a CustomResourceDefinition
+type crdVersion struct {
+	Name   string `json:"name"`
+	Served bool   `json:"served"`
+	Schema struct {
+		OpenAPIV3Schema map[string]any `json:"openAPIV3Schema"`
+	} `json:"schema"`
+}
+
+// crdSpec holds the fields of a CustomResourceDefinition needed to validate its instances
+type crdSpec struct {
+	Group string `json:"group"`
+	Names struct {
+		Kind string `json:"kind"`
+	} `json:"names"`
+	Versions []crdVersion `json:"versions"`
+
+	// Validation is the schema of all the versions in apiextensions.k8s.io/v1beta1
+	Validation struct {
+		OpenAPIV3Schema map[string]any `json:"openAPIV3Schema"`
+	} `json:"validation"`
+	// Version is the single version in apiextensions.k8s.io/v1beta1
+	Version string `json:"version"`
+}
+
+// isCRD checks if the object is a CustomResourceDefinition
+func isCRD(obj *fn.KubeObject) bool {
+	return obj.GetKind() == CRDKind && obj.GetAPIVersion() == CRDGroup+"/v1" ||
+		obj.GetKind() == CRDKind && obj.GetAPIVersion() == CRDGroup+"/v1beta1"
+}
+
+// crdSchema is the openAPIV3Schema of a served version of a CustomResourceDefinition
+type crdSchema struct {
+	kind       string
+	apiVersion string
+	// path is the path of the schema in the CRD e.g. spec.versions[0].schema.openAPIV3Schema
+	path   string
+	schema map[string]any
+}
+
+// crdSchemas returns the schemas of the served versions of the CRD, returns an
+// error result if the CRD is invalid
+func crdSchemas(obj *fn.KubeObject) ([]crdSchema, *fn.Result) {
+	var spec crdSpec
+	specObj := obj.GetMap("spec")
+	if specObj == nil {
+		return nil, ConfigObjectResult("CRD must have spec", "spec", obj, fn.Error)
+	}
+	if err := specObj.As(&spec); err != nil {
+		return nil, ConfigObjectResult(fmt.Sprintf("failed to read CRD: %s", err), "spec", obj, fn.Error)
+	}
+	if spec.Group == "" {
+		return nil, ConfigObjectResult("CRD must have spec.group", "spec.group", obj, fn.Error)
+	}
+	if spec.Names.Kind == "" {
+		return nil, ConfigObjectResult("CRD must have spec.names.kind", "spec.names.kind", obj, fn.Error)
+	}
+	if len(spec.Versions) == 0 && spec.Version != "" {
+		spec.Versions = []crdVersion{{Name: spec.Version, Served: true}}
+	}
+	var schemas []crdSchema
+	for i, v := range spec.Versions {
+		schema := v.Schema.OpenAPIV3Schema
+		path := fmt.Sprintf("spec.versions[%d].schema.openAPIV3Schema", i)
+		if schema == nil {
+			schema = spec.Validation.OpenAPIV3Schema
+			path = "spec.validation.openAPIV3Schema"
+		}
+		if !v.Served || schema == nil {
+			continue
+		}
+		schemas = append(schemas, crdSchema{
+			kind:       spec.Names.Kind,
+			apiVersion: spec.Group + "/" + v.Name,
+			path:       path,
+			schema:     withObjectMeta(schema),
+		})
+	}
+	return schemas, nil
+}
+
+// withObjectMeta adds the apiVersion, kind and metadata properties which the API server
+// implicitly adds to the schemas of the CRDs, so that they are allowed in strict mode
+func withObjectMeta(schema map[string]any) map[string]any {
+	props, ok := schema["properties"].(map[string]any)
+	if !ok {
+		return schema
+	}
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		out[k] = v
+	}
+	withMeta := make(map[string]any, len(props)+3)
+	for k, v := range props {
+		withMeta[k] = v
+	}
+	for name, typ := range map[string]string{"apiVersion": "string", "kind": "string", "metadata": "object"} {
+		if _, found := withMeta[name]; !found {
+			withMeta[name] = map[string]any{"type": typ}
+		}
+	}
+	out["properties"] = withMeta
+	return out
+}
+
+// addCRDs converts the openAPIV3Schema of each served version of the CRDs among the
+// objects into a json schema used to validate the instances of the CRD, the schemas of
+// the CRDs take precedence over the schema locations. Returns an error result for
+// each CRD whose schema can't be used.
+func (v *schemaValidator) addCRDs(objs []*fn.KubeObject) (fn.Results, error) {
+	var results fn.Results
+	for _, obj := range objs {
+		if !isCRD(obj) {
+			continue
+		}
+		schemas, result := crdSchemas(obj)
+		if result != nil {
+			results = append(results, result)
+			continue
+		}
+		for _, s := range schemas {
+			err := v.addSchema(s.kind, s.apiVersion, openAPIToJSONSchema(s.schema, v.strict))
+			var invalid *invalidSchemaError
+			if errors.As(err, &invalid) {
+				results = append(results, ConfigObjectResult(
+					fmt.Sprintf("invalid openAPIV3Schema for %s: %s", s.apiVersion, invalid.err), s.path, obj, fn.Error))
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// invalidSchemaError is returned by addSchema if the schema of the CRD can't be used
+type invalidSchemaError struct {
+	err error
+}
+
+func (e *invalidSchemaError) Error() string {
+	return e.err.Error()
+}
+
+// addSchema writes the json schema for the kind and apiVersion to the schema location
+// of the CRDs, the schema is compiled first so that an invalid schema is reported
+// instead of being skipped by kubeconform
+func (v *schemaValidator) addSchema(kind, apiVersion string, doc map[string]any) error {
+	key := apiVersion + "/" + kind
+	if v.crds[key] {
+		return &invalidSchemaError{fmt.Errorf("schema of %s %s is already defined by another CRD", apiVersion, kind)}
+	}
+	url := "crd://" + key + ".json"
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft4)
+	if err := compiler.AddResource(url, doc); err != nil {
+		return &invalidSchemaError{err}
+	}
+	if _, err := compiler.Compile(url); err != nil {
+		return &invalidSchemaError{err}
+	}
+	group, version, _ := strings.Cut(apiVersion, "/")
+	path := filepath.Join(v.crdDir, group, strings.ToLower(kind)+"_"+version+".json")
+	if err := writeSchema(path, doc); err != nil {
+		return err
+	}
+	v.crds[key] = true
+	return nil
+}
+
+// schemaKeywords are the keywords of a json schema whose values are schemas
+var schemaKeywords = map[string]bool{"items": true, "additionalProperties": true, "not": true}
+
+// schemaListKeywords are the keywords of a json schema whose values are lists of schemas
+var schemaListKeywords = map[string]bool{"items": true, "allOf": true, "anyOf": true, "oneOf": true}
+
+// schemaMapKeywords are the keywords of a json schema whose values are maps of schemas
+var schemaMapKeywords = map[string]bool{"properties": true, "patternProperties": true}
+
+/*
+openAPIToJSONSchema converts the OpenAPI v3 schema of a CRD into a json schema
+  - x-kubernetes-int-or-string fields accept integers and strings
+  - nullable fields also accept null
+  - in strict mode, objects with properties don't allow additional properties
+    unless x-kubernetes-preserve-unknown-fields is set
+
+Only the subschemas are converted, the values of the other keywords e.g. default,
+example and enum are copied as is.
+*/
+func openAPIToJSONSchema(schema map[string]any, strict bool) map[string]any {
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		switch val := v.(type) {
+		case map[string]any:
+			switch {
+			case schemaMapKeywords[k]:
+				props := make(map[string]any, len(val))
+				for name, prop := range val {
+					if m, ok := prop.(map[string]any); ok {
+						props[name] = openAPIToJSONSchema(m, strict)
+					} else {
+						props[name] = prop
+					}
+				}
+				out[k] = props
+			case schemaKeywords[k]:
+				out[k] = openAPIToJSONSchema(val, strict)
+			default:
+				out[k] = v
+			}
+		case []any:
+			if !schemaListKeywords[k] {
+				out[k] = v
+				continue
+			}
+			items := make([]any, len(val))
+			for i, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					items[i] = openAPIToJSONSchema(m, strict)
+				} else {
+					items[i] = item
+				}
+			}
+			out[k] = items
+		default:
+			out[k] = v
+		}
+	}
+
+	if intOrString, _ := out["x-kubernetes-int-or-string"].(bool); intOrString {
+		out["type"] = []any{"integer", "string"}
+	}
+	if nullable, _ := out["nullable"].(bool); nullable {
+		switch t := out["type"].(type) {
+		case string:
+			out["type"] = []any{t, "null"}
+		case []any:
+			out["type"] = append(t, "null")
+		}
+	}
+	preserveUnknown, _ := out["x-kubernetes-preserve-unknown-fields"].(bool)
+	if _, hasProps := out["properties"]; strict && hasProps && !preserveUnknown {
+		if _, ok := out["additionalProperties"]; !ok {
+			out["additionalProperties"] = false
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"sigs.k8s.io/yaml"
+)
+
+// deprecatedAPIsYAML is the table of the deprecated and removed APIs, it lives
+// next to the bundled schemas so that both are updated together
+//
+//go:embed jsonschema/deprecated-apis.yaml
+var deprecatedAPIsYAML []byte
+
+// deprecatedAPI is an apiVersion of some kinds which is deprecated and removed
+// in a Kubernetes version
+type deprecatedAPI struct {
+	APIVersion   string   `json:"apiVersion"`
+	Kinds        []string `json:"kinds"`
+	DeprecatedIn string   `json:"deprecatedIn"`
+	RemovedIn    string   `json:"removedIn"`
+	// Replacement is the apiVersion to use instead, empty if there is none
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// kubeVersion is the major and minor version of Kubernetes, the
+// zero value is the latest version i.e. master
+type kubeVersion struct {
+	major, minor int
+}
+
+func (v kubeVersion) isLatest() bool {
+	return v == kubeVersion{}
+}
+
+// less checks if v is before the other version
+func (v kubeVersion) less(other kubeVersion) bool {
+	if v.isLatest() {
+		return false
+	}
+	if other.isLatest() {
+		return true
+	}
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	return v.minor < other.minor
+}
+
+// parseKubeVersion parses the Kubernetes version e.g. master, 1.29, v1.29 or v1.29.3
+func parseKubeVersion(s string) (kubeVersion, error) {
+	if s == "" || s == DefaultKubernetesVersion {
+		return kubeVersion{}, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return kubeVersion{}, fmt.Errorf("invalid Kubernetes version %q, must be %s or MAJOR.MINOR[.PATCH]", s, DefaultKubernetesVersion)
+	}
+	var nums []int
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return kubeVersion{}, fmt.Errorf("invalid Kubernetes version %q, must be %s or MAJOR.MINOR[.PATCH]", s, DefaultKubernetesVersion)
+		}
+		nums = append(nums, n)
+	}
+	return kubeVersion{major: nums[0], minor: nums[1]}, nil
+}
+
+// deprecationChecker reports the objects using the APIs which are deprecated
+// or removed in the target Kubernetes version
+type deprecationChecker struct {
+	target kubeVersion
+	apis   map[string]deprecatedAPI
+}
+
+// newDeprecationChecker returns the checker of the bundled table for the target version
+func newDeprecationChecker(target string) (*deprecationChecker, error) {
+	version, err := parseKubeVersion(target)
+	if err != nil {
+		return nil, err
+	}
+	var table []deprecatedAPI
+	if err := yaml.Unmarshal(deprecatedAPIsYAML, &table); err != nil {
+		return nil, fmt.Errorf("failed to read the deprecated APIs: %w", err)
+	}
+	c := &deprecationChecker{target: version, apis: map[string]deprecatedAPI{}}
+	for _, api := range table {
+		for _, kind := range api.Kinds {
+			c.apis[api.APIVersion+"/"+kind] = api
+		}
+	}
+	return c, nil
+}
+
+// check returns an error result if the API of the object is removed in the target
+// version and a warning result if it is deprecated, removed is true if the object
+// can't be validated since its API doesn't exist anymore
+func (c *deprecationChecker) check(obj *fn.KubeObject) (result *fn.Result, removed bool) {
+	api, found := c.apis[obj.GetAPIVersion()+"/"+obj.GetKind()]
+	if !found {
+		return nil, false
+	}
+	replacement := "it has no replacement"
+	if api.Replacement != "" {
+		replacement = "use " + api.Replacement + " instead"
+	}
+	removedIn, _ := parseKubeVersion(api.RemovedIn)
+	if !c.target.less(removedIn) {
+		return ConfigObjectResult(
+			fmt.Sprintf("%s %s was removed in Kubernetes v%s, %s", api.APIVersion, obj.GetKind(), api.RemovedIn, replacement),
+			"apiVersion", obj, fn.Error), true
+	}
+	deprecatedIn, _ := parseKubeVersion(api.DeprecatedIn)
+	if !c.target.less(deprecatedIn) {
+		return ConfigObjectResult(
+			fmt.Sprintf("%s %s is deprecated since Kubernetes v%s and removed in v%s, %s",
+				api.APIVersion, obj.GetKind(), api.DeprecatedIn, api.RemovedIn, replacement),
+			"apiVersion", obj, fn.Warning), false
+	}
+	return nil, false
+}
@@ -3,15 +3,17 @@
 // Code generated by "mdtogo"; DO NOT EDIT.
 package generated
 
-var KubeconformShort = `The ` + "`" + `kubeconform` + "`" + ` function wraps the [` + "`" + `kubeconform` + "`" + `] binary to validate resources
-against their [json schemas].
+var KubeconformShort = `The ` + "`" + `kubeconform` + "`" + ` function validates resources against their [json schemas]
+with the validator of the [` + "`" + `kubeconform` + "`" + `] tool, without running an external binary.
 
 This function is often used in the following scenarios:
 
 - Validating resources as part of the local development workflow.
 - Validating resources in CI.`
 var KubeconformLong = `
-The function configuration must be a ConfigMap.
+The function configuration can be either a ConfigMap or a ` + "`" + `Kubeconform` + "`" + ` resource.
+
+ConfigMap:
 
 The following keys can be used in the ` + "`" + `data` + "`" + ` field of the ConfigMap, and all of
 them are optional:
@@ -26,9 +28,14 @@ them are optional:
 - ` + "`" + `ignore_missing_schemas` + "`" + `: Skip validation for resources without a schema. The
   default is ` + "`" + `false` + "`" + `.
 - ` + "`" + `skip_kinds` + "`" + `: Comma-separated list of case-sensitive kinds to skip when
-  validating against schemas. The default is empty.
+  validating against schemas, a kind can be qualified by its apiVersion,
+  e.g. ` + "`" + `apps/v1/Deployment` + "`" + `. The default is empty.
 - ` + "`" + `strict` + "`" + `: Disallow additional properties that are not in the schemas. The
   default is ` + "`" + `false` + "`" + `.
+- ` + "`" + `kubernetes_version` + "`" + `: The Kubernetes version of the target cluster, e.g.
+  ` + "`" + `"1.29"` + "`" + ` or ` + "`" + `"v1.29.3"` + "`" + `, quoted so that it is read as a string. It selects the
+  versioned schema directory, e.g. ` + "`" + `v1.29.3-standalone` + "`" + `, and the deprecated and
+  removed APIs to report. The default is ` + "`" + `master` + "`" + `, the latest version.
 
 The following is an example function configuration:
 
@@ -42,14 +49,101 @@ The following is an example function configuration:
     ignore_missing_schemas: "false"
     skip_kinds: "DaemonSet,MyCRD"
     strict: "true"
+    kubernetes_version: "1.29"
+
+Kubeconform:
+
+The ` + "`" + `Kubeconform` + "`" + ` resource has the following fields, all of them are optional:
+
+- ` + "`" + `schemaLocations` + "`" + `: List of the schema locations, in order of precedence.
+- ` + "`" + `skipKinds` + "`" + `: List of the kinds to skip, a kind can be qualified by its
+  apiVersion, e.g. ` + "`" + `apps/v1/Deployment` + "`" + `.
+- ` + "`" + `rejectKinds` + "`" + `: List of the kinds which are not allowed, each resource of
+  these kinds is an error.
+- ` + "`" + `severityOverrides` + "`" + `: Map of a kind to the severity of the schema validation
+  errors of its resources, one of ` + "`" + `error` + "`" + `, ` + "`" + `warning` + "`" + ` or ` + "`" + `info` + "`" + `, e.g. ` + "`" + `ConfigMap: warning` + "`" + `.
+  The apiVersion and kind takes precedence over the kind. The results of the
+  rejected kinds, removed APIs and missing schemas keep their severity, and the
+  resources failing the schema validation are counted as invalid in the summary
+  whatever the severity of their results.
+- ` + "`" + `ignoreMissingSchemas` + "`" + `: Skip validation for resources without a schema.
+- ` + "`" + `missingSchemasAsWarnings` + "`" + `: Report the resources without a schema as warnings
+  instead of errors.
+- ` + "`" + `strict` + "`" + `: Disallow additional properties that are not in the schemas.
+- ` + "`" + `kubernetesVersion` + "`" + `: The Kubernetes version of the target cluster.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: Kubeconform
+  metadata:
+    name: my-func-config
+  schemaLocations:
+  - file:///abs/path/to/your/schema/directory
+  - https://kubernetesjsonschema.dev
+  skipKinds:
+  - Kptfile
+  rejectKinds:
+  - v1/Secret
+  severityOverrides:
+    ConfigMap: warning
+  missingSchemasAsWarnings: true
+  strict: true
+  kubernetesVersion: "1.29"
+
+Schema locations:
+
+A schema location is either a template of the schema file path or URL, e.g.
+` + "`" + `file:///schemas/{{ .ResourceKind }}{{ .KindSuffix }}.json` + "`" + `, or a base directory or
+URL laid out like the [kubernetes-json-schema](https://github.com/yannh/kubernetes-json-schema/)
+repository, in which the schema of a ` + "`" + `Deployment` + "`" + ` of ` + "`" + `apps/v1` + "`" + ` is looked up at
+` + "`" + `master-standalone/deployment-apps-v1.json` + "`" + `, or at ` + "`" + `master-standalone-strict/deployment-apps-v1.json` + "`" + `
+if ` + "`" + `strict` + "`" + ` is ` + "`" + `true` + "`" + `. The same template variables as the [` + "`" + `kubeconform` + "`" + `] tool are supported:
+` + "`" + `NormalizedKubernetesVersion` + "`" + `, ` + "`" + `StrictSuffix` + "`" + `, ` + "`" + `ResourceKind` + "`" + `, ` + "`" + `ResourceAPIVersion` + "`" + `, ` + "`" + `Group` + "`" + `
+and ` + "`" + `KindSuffix` + "`" + `.
 
 If neither ` + "`" + `schema_location` + "`" + ` nor ` + "`" + `additional_schema_locations` + "`" + ` is provided, 
 the default baked-in schema will be used for validaton. The existing baked-in 
 schema was taken from the master branch of the [kubernetes-json-schema](https://github.com/yannh/kubernetes-json-schema/)
 repository, at [this commit](https://github.com/yannh/kubernetes-json-schema/commit/44df5137d11c91d2ab3311b42745d1ff37fda888).
 
+Results:
+
+Each result refers to the invalid field in ` + "`" + `field.path` + "`" + `, e.g. ` + "`" + `spec.replicas` + "`" + `,
+` + "`" + `spec` + "`" + ` for a missing ` + "`" + `spec.selector` + "`" + `, ` + "`" + `kind` + "`" + ` for a resource without a schema
+or ` + "`" + `apiVersion` + "`" + ` for a removed API. A last ` + "`" + `info` + "`" + ` result summarizes the number of
+valid and invalid resources per kind, e.g.
+` + "`" + `summary: Deployment: 2 valid, 1 invalid; Service: 1 valid, 0 invalid` + "`" + `.
+The function fails if any result is an error.
+
+Deprecated and removed APIs:
+
+The function reports the resources whose apiVersion is deprecated or removed in
+the target ` + "`" + `kubernetes_version` + "`" + `, together with the replacement apiVersion, e.g.
+` + "`" + `batch/v1beta1 CronJob was removed in Kubernetes v1.25, use batch/v1 instead` + "`" + `.
+A removed API is an error and the resource is not validated against a schema,
+a deprecated API which is still served is a warning. The table of the deprecated
+and removed APIs is ` + "`" + `jsonschema/deprecated-apis.yaml` + "`" + `, it is bundled into the
+function together with the schemas.
+
 Convert OpenAPI to JSON Schema:
 
-If you want to convert OpenAPI to json schema, you can use
-[openapi2jsonschema](https://github.com/instrumenta/openapi2jsonschema).
+The ` + "`" + `schema-bundle` + "`" + ` subcommand of the function binary builds a schema directory
+from CRD YAML files and from Kubernetes OpenAPI v3 documents, e.g. the output of
+` + "`" + `kubectl get --raw /openapi/v3/apis/apps/v1` + "`" + `. The schemas are written with the
+layout of the kubernetes-json-schema repository, in the ` + "`" + `-standalone` + "`" + ` and
+` + "`" + `-standalone-strict` + "`" + ` directories of the ` + "`" + `--kubernetes-version` + "`" + `, e.g.
+` + "`" + `v1.29-standalone/widget-example-v1.json` + "`" + ` for the ` + "`" + `Widget` + "`" + ` kind of ` + "`" + `example.com/v1` + "`" + `.
+The schemas of the CRDs take precedence over the schemas of the OpenAPI documents.
+As the file names only keep the first segment of the group, the subcommand fails if
+the schemas of a kind in two groups with the same first segment, e.g. ` + "`" + `example.com` + "`" + `
+and ` + "`" + `example.org` + "`" + `, would be written to the same file.
+
+  docker run --rm -v $(pwd):/work --entrypoint function ghcr.io/kptdev/krm-functions-catalog/kubeconform \
+    schema-bundle --crds /work/crds --openapi /work/apps-v1.json --kubernetes-version 1.29 --output /work/schemas
+
+The directory can be used as a ` + "`" + `schema_location` + "`" + `, or baked into an image for
+air-gapped environments, in which case the schemas are added to the bundled
+schemas:
+
+  FROM ghcr.io/kptdev/krm-functions-catalog/kubeconform
+  COPY schemas /jsonschema
 `
@@ -2,10 +2,13 @@
 set -euo pipefail
 
 # Helper script to update the built-in k8s schema used for validation.
+# Usage: ./update-schema.sh [KUBERNETES_VERSION], e.g. ./update-schema.sh v1.29.3
+# The schemas of a version are used by setting the kubernetes_version key
+# of the function config to the same version.
 
 REPO_URL="https://github.com/yannh/kubernetes-json-schema.git"
 TMP_DIR="tmp-jsonschema"
-K8S_VERSION="master"
+K8S_VERSION="${1:-master}"
 
  # folder with additional JSON schemas. 
  # Currently, we include a Kptfile schema by default (this is experimental).
@@ -13,9 +16,6 @@ CUSTOM_DIR="custom"
 
 echo "📦 Updating schemas for Kubernetes version: ${K8S_VERSION}"
 
-# NOTE: If building with a specific k8s version, the -kubernetes-version flag (default : master) must be
-# passed to the kubeconform cmd. See - https://github.com/yannh/kubeconform?tab=readme-ov-file#usage
-
 # 1️⃣ Create a temporary repo folder
 rm -rf "$TMP_DIR"
 mkdir -p "$TMP_DIR"
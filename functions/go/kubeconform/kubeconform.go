@@ -15,135 +15,201 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/yannh/kubeconform/pkg/validator"
 )
 
 const (
-	SchemaLocationStr = "-schema-location"
 	// FunctionConfig keys
 	SchemaLocationKey            = "schema_location"
 	AdditionalSchemaLocationsKey = "additional_schema_locations"
 	IgnoreMissingSchemasKey      = "ignore_missing_schemas"
 	SkipKindsKey                 = "skip_kinds"
 	StrictKey                    = "strict"
+	KubernetesVersionKey         = "kubernetes_version"
 )
 
 var DefaultSchemaLocation = "/jsonschema"
 
+// workers is the maximum number of resources validated concurrently
+var workers = runtime.NumCPU()
+
 type KubeconformConfig struct {
 	SchemaLocation            string
 	AdditionalSchemaLocations []string
 	IgnoreMissingSchemas      bool
+	MissingSchemasAsWarnings  bool
 	SkipKinds                 []string
+	RejectKinds               []string
+	SeverityOverrides         map[string]fn.Severity
 	Strict                    bool
+	KubernetesVersion         string
 }
 
-// Kubeconform represents the top-level structure returned by kubeconform.
-type Kubeconform struct {
-	Resources []Resource `json:"resources"`
-}
+func Run(rl *fn.ResourceList) (bool, error) {
+	cfg, err := getConfig(rl.FunctionConfig)
+	if err != nil {
+		return false, err
+	}
+	checker, err := newDeprecationChecker(cfg.KubernetesVersion)
+	if err != nil {
+		return false, err
+	}
+	v, err := newSchemaValidator(cfg)
+	if err != nil {
+		return false, err
+	}
+	defer v.Close()
 
-// Resource represents the validation result for a single resource.
-type Resource struct {
-	Filename         string            `json:"filename"`
-	Kind             string            `json:"kind"`
-	Name             string            `json:"name"`
-	Version          string            `json:"version"`
-	Status           string            `json:"status"` // e.g. "statusValid" or "statusInvalid"
-	Message          string            `json:"msg"`
-	ValidationErrors []ValidationError `json:"validationErrors"`
-}
+	// the CRDs in the package are used to validate their instances
+	crdResults, err := v.addCRDs(rl.Items)
+	if err != nil {
+		return false, err
+	}
 
-// ValidationError provides detailed schema validation issues.
-type ValidationError struct {
-	Path string `json:"path"`
-	Msg  string `json:"msg"`
-}
+	// validate the objects concurrently, the results are collected per
+	// object so that they are reported in the order of the objects
+	objResults := make([]fn.Results, len(rl.Items))
+	invalid := make([]bool, len(rl.Items))
+	errs := make([]error, len(rl.Items))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				objResults[i], invalid[i], errs[i] = validateObject(rl.Items[i], v, checker, cfg)
+			}
+		}()
+	}
+	for i := range rl.Items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
 
-func Run(rl *fn.ResourceList) (bool, error) {
-	cfg := extractConfig(rl.FunctionConfig)
-	args := buildKubeconformArgs(
-		cfg.SchemaLocation,
-		cfg.AdditionalSchemaLocations,
-		cfg.IgnoreMissingSchemas,
-		cfg.SkipKinds,
-		cfg.Strict,
-	)
+	results := crdResults
+	hasValidationErrors := len(crdResults) > 0
+	counts := map[string]*kindCount{}
+	for i, obj := range rl.Items {
+		if errs[i] != nil {
+			return false, errs[i]
+		}
+		results = append(results, objResults[i]...)
+		for _, result := range objResults[i] {
+			if result.Severity == fn.Error {
+				hasValidationErrors = true
+			}
+		}
+		if matchKind(obj, cfg.SkipKinds) && !matchKind(obj, cfg.RejectKinds) {
+			continue
+		}
 
-	var results fn.Results
-	hasValidationErrors := false
-	for _, obj := range rl.Items {
-		objResults, err := runKubeconformForObject(obj, args)
-		if err != nil {
-			return false, err
+		if counts[obj.GetKind()] == nil {
+			counts[obj.GetKind()] = &kindCount{}
 		}
-		if len(objResults) > 0 {
-			hasValidationErrors = true
+		if invalid[i] {
+			counts[obj.GetKind()].invalid++
+		} else {
+			counts[obj.GetKind()].valid++
 		}
-		results = append(results, objResults...)
 	}
 
 	rl.Results = append(rl.Results, results...)
+	rl.Results = append(rl.Results, summaryResult(counts))
 	return !hasValidationErrors, nil
 }
 
-func runKubeconformForObject(obj *fn.KubeObject, args []string) (fn.Results, error) {
-	cmd := exec.Command("kubeconform", args...)
-
-	data, err := marshalKubeObject(obj)
+// validateObject checks that the kind of the object is allowed and that its API is not
+// deprecated or removed in the target Kubernetes version, validates the object against
+// the schema of its kind and returns a result for each validation error. The severity
+// overrides only apply to the schema validation errors, the object is invalid if it
+// has any error or fails the schema validation whatever the severity of the results.
+func validateObject(obj *fn.KubeObject, v *schemaValidator, checker *deprecationChecker, cfg KubeconformConfig) (fn.Results, bool, error) {
+	results, schemaErrors, err := checkObject(obj, v, checker, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal object: %w", err)
+		return nil, false, err
 	}
-	cmd.Stdin = bytes.NewReader(data)
-	var stdout bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = os.Stderr
-
-	err = cmd.Run()
-	if err != nil {
-		if stdout.Len() == 0 {
-			return nil, fmt.Errorf("failed to run kubeconform: %w", err)
+	invalid := len(schemaErrors) > 0
+	for _, result := range results {
+		if result.Severity == fn.Error {
+			invalid = true
 		}
 	}
-
-	raw := stdout.Bytes()
-	if bytes.Contains(raw, []byte("Failed initializing schema file")) {
-		return fn.Results{
-			fn.ErrorConfigObjectResult(
-				fmt.Errorf("%s", fmt.Sprintf("Validating arbitrary CRDs not supported. Consider setting %s or %s in FunctionConfig:\n%s",
-					IgnoreMissingSchemasKey, SkipKindsKey, string(raw))),
-				obj,
-			),
-		}, nil
+	if severity, ok := severityOverride(obj, cfg.SeverityOverrides); ok {
+		for _, result := range schemaErrors {
+			result.Severity = severity
+		}
 	}
+	return append(results, schemaErrors...), invalid, nil
+}
 
-	var kubeConf Kubeconform
-	if err := json.Unmarshal(raw, &kubeConf); err != nil {
+// checkObject returns the results of the checks of the object and the results of
+// the schema validation errors, which are reported after the other results
+func checkObject(obj *fn.KubeObject, v *schemaValidator, checker *deprecationChecker, cfg KubeconformConfig) (fn.Results, fn.Results, error) {
+	if matchKind(obj, cfg.RejectKinds) {
 		return fn.Results{
-			fn.ErrorConfigObjectResult(
-				fmt.Errorf("%s", fmt.Sprintf("Failed to parse kubeconform output:\n%s\n%s", err, string(raw))),
-				obj,
-			),
-		}, nil
+			ConfigObjectResult(fmt.Sprintf("%s %s is not allowed", obj.GetAPIVersion(), obj.GetKind()), "kind", obj, fn.Error),
+		}, nil, nil
+	}
+	if matchKind(obj, cfg.SkipKinds) {
+		return nil, nil, nil
+	}
+	if obj.GetAPIVersion() == "" {
+		return fn.Results{ConfigObjectResult("missing apiVersion", "apiVersion", obj, fn.Error)}, nil, nil
+	}
+	if obj.GetKind() == "" {
+		return fn.Results{ConfigObjectResult("missing kind", "kind", obj, fn.Error)}, nil, nil
 	}
 
 	var results fn.Results
-	for _, res := range kubeConf.Resources {
-		if res.Status != "statusValid" {
-			for _, e := range res.ValidationErrors {
-				results = append(results, ConfigObjectResult(e.Msg, e.Path, obj, fn.Error))
-			}
-		}
+	deprecation, removed := checker.check(obj)
+	if deprecation != nil {
+		results = append(results, deprecation)
+	}
+	if removed {
+		return results, nil, nil
 	}
 
-	return results, nil
+	data, err := marshalKubeObject(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal object: %w", err)
+	}
+	res := v.validate(data)
+	switch res.Status {
+	case validator.Skipped:
+		// kubeconform skips the resources without a schema
+		if cfg.IgnoreMissingSchemas {
+			return results, nil, nil
+		}
+		severity := fn.Error
+		if cfg.MissingSchemasAsWarnings {
+			severity = fn.Warning
+		}
+		return append(results, ConfigObjectResult(
+			fmt.Sprintf("could not find schema for %s %s. Consider adding its CustomResourceDefinition to the package, skipping its kind or ignoring missing schemas in FunctionConfig",
+				obj.GetAPIVersion(), obj.GetKind()),
+			"kind", obj, severity)), nil, nil
+	case validator.Invalid:
+		if len(res.ValidationErrors) == 0 {
+			return results, fn.Results{fn.ErrorConfigObjectResult(res.Err, obj)}, nil
+		}
+		var schemaErrors fn.Results
+		for _, e := range res.ValidationErrors {
+			schemaErrors = append(schemaErrors, ConfigObjectResult(e.Msg, e.Path, obj, fn.Error))
+		}
+		return results, schemaErrors, nil
+	case validator.Error:
+		return append(results, fn.ErrorConfigObjectResult(res.Err, obj)), nil, nil
+	}
+	return results, nil, nil
 }
 
 func ConfigObjectResult(msg string, path string, obj *fn.KubeObject, severity fn.Severity) *fn.Result {
@@ -201,39 +267,10 @@ func extractConfig(fc *fn.KubeObject) KubeconformConfig {
 		IgnoreMissingSchemas:      getBool(IgnoreMissingSchemasKey),
 		SkipKinds:                 getStringList(SkipKindsKey),
 		Strict:                    getBool(StrictKey),
+		KubernetesVersion:         getString(KubernetesVersionKey),
 	}
 }
 
-func buildKubeconformArgs(schemaLocation string, additional []string, ignoreMissing bool, skipKinds []string, strict bool) []string {
-	args := []string{"-output", "json"}
-
-	// Use specified schema locations or fallback to /jsonschema/* directories
-	if schemaLocation != "" {
-		args = append(args, SchemaLocationStr, schemaLocation)
-	}
-
-	if len(additional) > 0 {
-		for _, add := range additional {
-			args = append(args, SchemaLocationStr, add)
-		}
-	}
-
-	if schemaLocation == "" && len(additional) == 0 {
-		args = append(args, SchemaLocationStr, "file://"+DefaultSchemaLocation)
-	}
-
-	if ignoreMissing {
-		args = append(args, "-ignore-missing-schemas")
-	}
-	if len(skipKinds) > 0 {
-		args = append(args, "-skip", strings.Join(skipKinds, ","))
-	}
-	if strict {
-		args = append(args, "-strict")
-	}
-	return args
-}
-
 func marshalKubeObject(obj *fn.KubeObject) ([]byte, error) {
 	// Convert KubeObject to a typed object (e.g., map[string]interface{})
 	var typed map[string]interface{}
@@ -42,6 +42,7 @@ data:
   ignore_missing_schemas: "true"
   skip_kinds: MyCustom,AnotherKind
   strict: "true"
+  kubernetes_version: "1.29.3"
 `,
 			expected: KubeconformConfig{
 				SchemaLocation:            "/schemas",
@@ -49,6 +50,7 @@ data:
 				IgnoreMissingSchemas:      true,
 				SkipKinds:                 []string{"MyCustom", "AnotherKind"},
 				Strict:                    true,
+				KubernetesVersion:         "1.29.3",
 			},
 		},
 		{
@@ -107,58 +109,11 @@ data:
 			if cfg.Strict != tt.expected.Strict {
 				t.Errorf("Strict: got %v, want %v", cfg.Strict, tt.expected.Strict)
 			}
-		})
-	}
-}
-
-func TestBuildKubeconformArgs(t *testing.T) {
-
-	tests := []struct {
-		name              string
-		schemaLocation    string
-		additionalSchemas []string
-		ignoreMissing     bool
-		skipKinds         []string
-		strict            bool
-		wantContains      []string
-	}{
-		{
-			name:           "default schema",
-			schemaLocation: "",
-			wantContains:   []string{SchemaLocationStr, "file://" + DefaultSchemaLocation},
-		},
-		{
-			name:           "with schema location",
-			schemaLocation: "/my/schema",
-			wantContains:   []string{SchemaLocationStr, "/my/schema"},
-		},
-		{
-			name:              "with additional schema",
-			additionalSchemas: []string{"/my/schema1", "/their/schema2"},
-			wantContains:      []string{SchemaLocationStr, "/my/schema1", SchemaLocationStr, "/their/schema2"},
-		},
-		{
-			name:          "ignore missing + strict",
-			ignoreMissing: true,
-			strict:        true,
-			wantContains:  []string{"-ignore-missing-schemas", "-strict"},
-		},
-		{
-			name:         "skip kinds",
-			skipKinds:    []string{"Pod", "Deployment"},
-			wantContains: []string{"-skip", "Pod,Deployment"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := buildKubeconformArgs(tt.schemaLocation, tt.additionalSchemas, tt.ignoreMissing, tt.skipKinds, tt.strict)
-			for _, want := range tt.wantContains {
-				assert.Contains(t, got, want)
+			if cfg.KubernetesVersion != tt.expected.KubernetesVersion {
+				t.Errorf("KubernetesVersion: got %q, want %q", cfg.KubernetesVersion, tt.expected.KubernetesVersion)
 			}
 		})
 	}
-
 }
 
 func TestMarshalKubeObject(t *testing.T) {
@@ -177,38 +132,50 @@ func TestMarshalKubeObject(t *testing.T) {
 
 }
 
-func TestRunKubeconformForObject(t *testing.T) {
-	tmpDir := t.TempDir()
-	mockPath := filepath.Join(tmpDir, "kubeconform")
-	mockOutput := `{
-  "resources": [
-    {
-      "filename": "kubeval-simple/resources.yaml",
-      "kind": "ReplicationController",
-      "name": "bob",
-      "version": "v1",
-      "status": "statusInvalid",
-      "msg": "problem validating schema. Check JSON formatting: jsonschema validation failed with 'https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/master-standalone-strict/replicationcontroller-v1.json#' - at '/spec/replicas': got string, want null or integer - at '/spec': additional properties 'templates' not allowed",
-      "validationErrors": [
-        {
-          "path": "/spec/replicas",
-          "msg": "got string, want null or integer"
-        },
-        {
-          "path": "/spec",
-          "msg": "additional properties 'templates' not allowed"
-        }
-      ]
+// writeSchemas writes the schemas to a schema directory with the layout of the
+// kubernetes-json-schema repository and returns the directory
+func writeSchemas(t *testing.T, schemas map[string]string) string {
+	dir := t.TempDir()
+	for name, schema := range schemas {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(schema), 0600))
+	}
+	return dir
+}
+
+const replicationControllerSchema = `{
+  "type": "object",
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object"},
+    "spec": {
+      "type": "object",
+      "properties": {
+        "replicas": {"type": ["integer", "null"]},
+        "selector": {"type": "object"},
+        "template": {"type": "object"}
+      },
+      "additionalProperties": false
     }
-  ]
+  }
+}`
+
+const podSchema = `{
+  "type": "object",
+  "required": ["metadata"],
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object"}
+  }
 }`
 
-	_ = os.WriteFile(mockPath, fmt.Appendf(nil, "#!/bin/sh\necho '%s'\n", mockOutput), 0755)
-	oldPath := os.Getenv("PATH")
-	// nolint
-	os.Setenv("PATH", tmpDir+":"+oldPath)
-	// nolint
-	defer os.Setenv("PATH", oldPath)
+func TestValidateObject(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"master-standalone-strict/replicationcontroller-v1.json": replicationControllerSchema,
+	})
 
 	yamlStr := `
 apiVersion: v1
@@ -238,23 +205,24 @@ spec:
 		panic(fmt.Errorf("failed to parse object: %w", err))
 	}
 
-	results, _ := runKubeconformForObject(obj, []string{})
+	cfg := KubeconformConfig{SchemaLocation: dir, Strict: true}
+	v, err := newSchemaValidator(cfg)
+	assert.NoError(t, err)
+	defer v.Close()
+	checker, err := newDeprecationChecker(cfg.KubernetesVersion)
+	assert.NoError(t, err)
+	results, invalid, err := validateObject(obj, v, checker, cfg)
+	assert.NoError(t, err)
+	assert.True(t, invalid)
 	assert.Len(t, results, 2)
 	assert.Contains(t, results[0].String(), "spec.replicas: got string, want null or integer")
-	assert.Contains(t, results[1].String(), "spec: additional properties templates not allowed")
+	assert.Contains(t, results[1].String(), "spec: additional properties 'templates' not allowed")
 }
 
 func TestRunWithFakeFunctionConfig(t *testing.T) {
-	tmpDir := t.TempDir()
-	mockPath := filepath.Join(tmpDir, "kubeconform")
-	mockOutput := `{"resources": []}`
-	_ = os.WriteFile(mockPath, fmt.Appendf(nil, "#!/bin/sh\necho '%s'\n", mockOutput), 0755)
-
-	oldPath := os.Getenv("PATH")
-	// nolint
-	os.Setenv("PATH", tmpDir+":"+oldPath)
-	// nolint
-	defer os.Setenv("PATH", oldPath)
+	dir := writeSchemas(t, map[string]string{
+		"master-standalone-strict/pod-v1.json": podSchema,
+	})
 
 	pod := fn.NewEmptyKubeObject()
 	_ = pod.SetAPIVersion("v1")
@@ -262,8 +230,8 @@ func TestRunWithFakeFunctionConfig(t *testing.T) {
 	_ = pod.SetName("mypod")
 
 	fc := fn.NewEmptyKubeObject()
-	_ = fc.SetAnnotation(SchemaLocationKey, "/jsonschema")
-	_ = fc.SetAnnotation(StrictKey, "true")
+	_ = fc.SetNestedField(dir, SchemaLocationKey)
+	_ = fc.SetNestedField(true, StrictKey)
 
 	rl := &fn.ResourceList{
 		FunctionConfig: fc,
@@ -273,5 +241,374 @@ func TestRunWithFakeFunctionConfig(t *testing.T) {
 	isValid, err := Run(rl)
 	assert.NoError(t, err)
 	assert.True(t, isValid)
-	assert.Empty(t, rl.Results)
+	if assert.Len(t, rl.Results, 1) {
+		assert.Equal(t, "summary: Pod: 1 valid, 0 invalid", rl.Results[0].Message)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"master-standalone/replicationcontroller-v1.json": replicationControllerSchema,
+		"master-standalone/pod-v1.json":                   podSchema,
+	})
+
+	var items []*fn.KubeObject
+	for i := 0; i < 50; i++ {
+		obj, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  name: rc-%d
+spec:
+  replicas: invalid-%d
+`, i, i)))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+	for _, yamlStr := range []string{`
+apiVersion: example.com/v1
+kind: MyCustom
+metadata:
+  name: custom
+`, `
+apiVersion: example.com/v1
+kind: Skipped
+metadata:
+  name: skipped
+`} {
+		obj, err := fn.ParseKubeObject([]byte(yamlStr))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+
+	fc, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  schema_location: %s
+  skip_kinds: example.com/v1/Skipped
+`, dir)))
+	assert.NoError(t, err)
+
+	rl := &fn.ResourceList{
+		FunctionConfig: fc,
+		Items:          items,
+	}
+	isValid, err := Run(rl)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+	if !assert.Len(t, rl.Results, 52) {
+		t.FailNow()
+	}
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, fmt.Sprintf("rc-%d", i), rl.Results[i].ResourceRef.Name)
+		assert.Equal(t, "spec.replicas", rl.Results[i].Field.Path)
+	}
+	assert.Contains(t, rl.Results[50].Message, "could not find schema for example.com/v1 MyCustom")
+	assert.Equal(t, "kind", rl.Results[50].Field.Path)
+	assert.Equal(t, "summary: MyCustom: 0 valid, 1 invalid; ReplicationController: 0 valid, 50 invalid", rl.Results[51].Message)
+
+	_ = fc.SetNestedField("true", "data", IgnoreMissingSchemasKey)
+	rl = &fn.ResourceList{
+		FunctionConfig: fc,
+		Items:          items[50:],
+	}
+	isValid, err = Run(rl)
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+	if assert.Len(t, rl.Results, 1) {
+		assert.Equal(t, "summary: MyCustom: 1 valid, 0 invalid", rl.Results[0].Message)
+	}
+}
+
+func TestRunWithCRD(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{})
+
+	var items []*fn.KubeObject
+	for _, yamlStr := range []string{`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: [size]
+            properties:
+              size:
+                type: string
+              port:
+                x-kubernetes-int-or-string: true
+              owner:
+                type: string
+                nullable: true
+  - name: v1alpha1
+    served: false
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+`, `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: valid
+spec:
+  size: large
+  port: 8080
+  owner: null
+`, `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: invalid
+spec:
+  port: 1.5
+`, `
+apiVersion: example.com/v1alpha1
+kind: Widget
+metadata:
+  name: not-served
+`} {
+		obj, err := fn.ParseKubeObject([]byte(yamlStr))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+
+	fc, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  schema_location: %s
+  skip_kinds: CustomResourceDefinition
+`, dir)))
+	assert.NoError(t, err)
+
+	rl := &fn.ResourceList{
+		FunctionConfig: fc,
+		Items:          items,
+	}
+	isValid, err := Run(rl)
+	assert.NoError(t, err)
+	assert.False(t, isValid)
+	if !assert.Len(t, rl.Results, 4) {
+		t.FailNow()
+	}
+	for _, result := range rl.Results[:2] {
+		assert.Equal(t, "invalid", result.ResourceRef.Name)
+	}
+	assert.Contains(t, rl.Results[0].Message, "missing property 'size'")
+	assert.Equal(t, "spec", rl.Results[0].Field.Path)
+	assert.Equal(t, "spec.port", rl.Results[1].Field.Path)
+	assert.Contains(t, rl.Results[2].Message, "could not find schema for example.com/v1alpha1 Widget")
+	assert.Equal(t, "summary: Widget: 1 valid, 2 invalid", rl.Results[3].Message)
+}
+
+func TestOpenAPIToJSONSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"config": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"port": map[string]any{"x-kubernetes-int-or-string": true},
+				},
+				// the payloads of default, example and enum are values, not schemas
+				"default": map[string]any{
+					"properties": map[string]any{"nullable": true},
+				},
+				"example": map[string]any{
+					"items": map[string]any{"x-kubernetes-int-or-string": true},
+				},
+				"enum": []any{
+					map[string]any{"properties": map[string]any{"a": map[string]any{"nullable": true}}},
+				},
+			},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string", "nullable": true},
+			},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string", "nullable": true},
+			},
+			"mode": map[string]any{
+				"anyOf": []any{map[string]any{"type": "string", "nullable": true}},
+				"not":   map[string]any{"type": "integer", "nullable": true},
+			},
+		},
+	}
+	expected := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"apiVersion": map[string]any{"type": "string"},
+			"kind":       map[string]any{"type": "string"},
+			"metadata":   map[string]any{"type": "object"},
+			"config": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"port": map[string]any{"x-kubernetes-int-or-string": true, "type": []any{"integer", "string"}},
+				},
+				"additionalProperties": false,
+				"default": map[string]any{
+					"properties": map[string]any{"nullable": true},
+				},
+				"example": map[string]any{
+					"items": map[string]any{"x-kubernetes-int-or-string": true},
+				},
+				"enum": []any{
+					map[string]any{"properties": map[string]any{"a": map[string]any{"nullable": true}}},
+				},
+			},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": []any{"string", "null"}, "nullable": true},
+			},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": []any{"string", "null"}, "nullable": true},
+			},
+			"mode": map[string]any{
+				"anyOf": []any{map[string]any{"type": []any{"string", "null"}, "nullable": true}},
+				"not":   map[string]any{"type": []any{"integer", "null"}, "nullable": true},
+			},
+		},
+		"additionalProperties": false,
+	}
+	assert.Equal(t, expected, openAPIToJSONSchema(withObjectMeta(schema), true))
+}
+
+func TestParseKubeVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected kubeVersion
+		errMsg   string
+	}{
+		{version: "", expected: kubeVersion{}},
+		{version: "master", expected: kubeVersion{}},
+		{version: "1.25", expected: kubeVersion{major: 1, minor: 25}},
+		{version: "v1.29.3", expected: kubeVersion{major: 1, minor: 29}},
+		{version: "1", errMsg: `invalid Kubernetes version "1"`},
+		{version: "latest", errMsg: `invalid Kubernetes version "latest"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			v, err := parseKubeVersion(tt.version)
+			if tt.errMsg != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.errMsg)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, v)
+		})
+	}
+}
+
+func TestRunWithKubernetesVersion(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{
+		"v1.24-standalone/pod-v1.json":                podSchema,
+		"v1.24-standalone/cronjob-batch-v1beta1.json": podSchema,
+		"v1.25.0-standalone/pod-v1.json":              podSchema,
+	})
+
+	var items []*fn.KubeObject
+	for _, yamlStr := range []string{`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+`, `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: cronjob
+`, `
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: ingress
+`, `
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: psp
+`} {
+		obj, err := fn.ParseKubeObject([]byte(yamlStr))
+		assert.NoError(t, err)
+		items = append(items, obj)
+	}
+
+	tests := []struct {
+		version  string
+		isValid  bool
+		expected []string
+	}{
+		{
+			version: "1.24",
+			isValid: false,
+			expected: []string{
+				"[warning] batch/v1beta1/CronJob/cronjob apiVersion: batch/v1beta1 CronJob is deprecated since Kubernetes v1.21 and removed in v1.25, use batch/v1 instead",
+				"[error] extensions/v1beta1/Ingress/ingress apiVersion: extensions/v1beta1 Ingress was removed in Kubernetes v1.22, use networking.k8s.io/v1 instead",
+				"[warning] policy/v1beta1/PodSecurityPolicy/psp apiVersion: policy/v1beta1 PodSecurityPolicy is deprecated since Kubernetes v1.21 and removed in v1.25, it has no replacement",
+				"[error] policy/v1beta1/PodSecurityPolicy/psp kind: could not find schema for policy/v1beta1 PodSecurityPolicy",
+				"[info]: summary: CronJob: 1 valid, 0 invalid; Ingress: 0 valid, 1 invalid; Pod: 1 valid, 0 invalid; PodSecurityPolicy: 0 valid, 1 invalid",
+			},
+		},
+		{
+			version: "v1.25.0",
+			isValid: false,
+			expected: []string{
+				"[error] batch/v1beta1/CronJob/cronjob apiVersion: batch/v1beta1 CronJob was removed in Kubernetes v1.25, use batch/v1 instead",
+				"[error] extensions/v1beta1/Ingress/ingress apiVersion: extensions/v1beta1 Ingress was removed in Kubernetes v1.22, use networking.k8s.io/v1 instead",
+				"[error] policy/v1beta1/PodSecurityPolicy/psp apiVersion: policy/v1beta1 PodSecurityPolicy was removed in Kubernetes v1.25, it has no replacement",
+				"[info]: summary: CronJob: 0 valid, 1 invalid; Ingress: 0 valid, 1 invalid; Pod: 1 valid, 0 invalid; PodSecurityPolicy: 0 valid, 1 invalid",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			fc, err := fn.ParseKubeObject([]byte(fmt.Sprintf(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn-config
+data:
+  schema_location: %s
+  kubernetes_version: "%s"
+`, dir, tt.version)))
+			assert.NoError(t, err)
+			rl := &fn.ResourceList{
+				FunctionConfig: fc,
+				Items:          items,
+			}
+			isValid, err := Run(rl)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.isValid, isValid)
+			if !assert.Len(t, rl.Results, len(tt.expected)) {
+				t.FailNow()
+			}
+			for i, expected := range tt.expected {
+				assert.Contains(t, rl.Results[i].String(), expected)
+			}
+		})
+	}
 }
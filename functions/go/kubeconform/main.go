@@ -23,6 +23,7 @@ import (
 
 func main() {
 	cmd := &cobra.Command{
+		Use:   "function",
 		Short: generated.KubeconformShort,
 		Long:  generated.KubeconformLong,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -32,6 +33,8 @@ func main() {
 		SilenceErrors: true,
 	}
 
+	cmd.AddCommand(newSchemaBundleCommand())
+
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -0,0 +1,116 @@
+// Copyright (C) 2025 OpenInfra Foundation Europe
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yannh/kubeconform/pkg/resource"
+	"github.com/yannh/kubeconform/pkg/validator"
+)
+
+// DefaultKubernetesVersion is the version of the schemas looked up in the schema locations
+const DefaultKubernetesVersion = "master"
+
+// crdSchemaTemplate is the layout of the schemas of the CRDs of the package in
+// the temporary schema location e.g. example.com/widget_v1.json
+const crdSchemaTemplate = "{{ .Group }}/{{ .ResourceKind }}_{{ .ResourceAPIVersion }}.json"
+
+/*
+schemaValidator validates the resources with the kubeconform validator, which loads
+and compiles the schema of each kind once and is safe for concurrent use.
+
+The kubeconform validator only reads the schemas from schema locations, so the schemas
+of the CRDs of the package are written to a temporary schema location which takes
+precedence over the configured schema locations.
+*/
+type schemaValidator struct {
+	validator validator.Validator
+	strict    bool
+
+	// crdDir is the temporary schema location of the schemas of the CRDs
+	crdDir string
+
+	// crds are the kinds whose schemas are defined by CRDs
+	crds map[string]bool
+}
+
+// newSchemaValidator returns the validator for the schema locations and the Kubernetes
+// version of the config, the default schema location is used if none is provided.
+// Close must be called to remove the schemas of the CRDs.
+func newSchemaValidator(cfg KubeconformConfig) (*schemaValidator, error) {
+	var locations []string
+	if cfg.SchemaLocation != "" {
+		locations = append(locations, cfg.SchemaLocation)
+	}
+	locations = append(locations, cfg.AdditionalSchemaLocations...)
+	if len(locations) == 0 {
+		locations = append(locations, "file://"+DefaultSchemaLocation)
+	}
+
+	crdDir, err := os.MkdirTemp("", "kubeconform-crds-")
+	if err != nil {
+		return nil, err
+	}
+	locations = append([]string{"file://" + filepath.ToSlash(crdDir) + "/" + crdSchemaTemplate}, locations...)
+
+	// kubeconform adds the v prefix to the Kubernetes version of the schema locations
+	kubernetesVersion := strings.TrimPrefix(cfg.KubernetesVersion, "v")
+	if kubernetesVersion == "" {
+		kubernetesVersion = DefaultKubernetesVersion
+	}
+	// the missing schemas are skipped by kubeconform so that they are
+	// reported according to the config
+	v, err := validator.New(locations, validator.Opts{
+		KubernetesVersion:    kubernetesVersion,
+		Strict:               cfg.Strict,
+		IgnoreMissingSchemas: true,
+	})
+	if err != nil {
+		_ = os.RemoveAll(crdDir)
+		return nil, err
+	}
+	return &schemaValidator{
+		validator: v,
+		strict:    cfg.Strict,
+		crdDir:    crdDir,
+		crds:      map[string]bool{},
+	}, nil
+}
+
+// Close removes the schemas of the CRDs
+func (v *schemaValidator) Close() error {
+	return os.RemoveAll(v.crdDir)
+}
+
+// validate validates the resource in JSON against the schema of its kind
+func (v *schemaValidator) validate(data []byte) validator.Result {
+	return v.validator.ValidateResource(resource.Resource{Bytes: data})
+}
+
+// writeSchema writes the json schema to the file, the parent directories are created
+func writeSchema(path string, schema map[string]any) error {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644) // nolint:gosec
+}
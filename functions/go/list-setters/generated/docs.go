@@ -17,6 +17,35 @@ var ListSettersLong = `
 
 1. Searches for setter comments in input list of resources.
 1. Lists discovered setters and related information.
+
+Optionally, a ` + "`" + `ConfigMap` + "`" + ` can be provided to validate the setters or to also write a machine-readable
+description of the setters into the package.
+
+  apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: list-setters-fn-config
+  data:
+    validate: "true"
+    output: json-schema
+    output-path: setters-schema.yaml
+
+- ` + "`" + `validate` + "`" + `: when ` + "`" + `"true"` + "`" + `, the setters are also validated and each problem is reported as a result
+  with the file and field path of the field:
+  - error for each field of a setter whose fields have different values, e.g. ` + "`" + `${env}` + "`" + ` resolving to
+    ` + "`" + `dev` + "`" + ` in one file and ` + "`" + `prod` + "`" + ` in another.
+  - error for each field whose setter value doesn't match the ` + "`" + `apply-setters` + "`" + ` config in the Kptfile.
+  - error for each setter comment whose pattern can't be resolved from the current field value.
+  - warning for each setter declared in the Kptfile which doesn't parameterize any field.
+
+- ` + "`" + `output` + "`" + `: ` + "`" + `setters` + "`" + ` writes a local-config ` + "`" + `Setters` + "`" + ` resource, and ` + "`" + `json-schema` + "`" + ` writes a local-config
+  ` + "`" + `SettersSchema` + "`" + ` resource holding the JSON Schema of the setters under the ` + "`" + `schema` + "`" + ` field.
+- ` + "`" + `output-path` + "`" + `: file path of the written resource, defaults to ` + "`" + `setters.yaml` + "`" + ` for ` + "`" + `setters` + "`" + ` and
+  ` + "`" + `setters-schema.yaml` + "`" + ` for ` + "`" + `json-schema` + "`" + `.
+
+Each setter is described with its type inferred from the YAML tags of the field values, its current value,
+the type of the items of array setters, the file and field path of every field parameterized by the setter,
+and a description taken from the comment above the first parameterized field.
 `
 var ListSettersExamples = `
 ### Listing setters in a package
@@ -27,8 +27,32 @@ type ListSetters struct {
 	// Warnings holds recoverable error info that occurred during setter discovery
 	Warnings []*WarnSetterDiscovery
 
+	// Output is the output mode, either OutputSetters or OutputJSONSchema,
+	// results are only listed if it is empty
+	Output string
+
+	// OutputPath is the file path of the resource written by the output mode
+	OutputPath string
+
+	// Validate reports the inconsistent setters as issues
+	Validate bool
+
 	// filePath file path of resource
 	filePath string
+
+	// descriptions holds the setter names to the comments
+	// adjacent to the fields parameterized by the setters
+	descriptions map[string]string
+
+	// kptfileSetters holds the setters declared in the Kptfile
+	kptfileSetters map[string]string
+
+	// usages holds the setter names to the fields parameterized by the setters
+	usages map[string][]usage
+
+	// unresolved holds the issues of the setter comments which can't be
+	// resolved from the field values
+	unresolved []*Issue
 }
 
 // ScalarSetter stores name, value and count of the scalar setter
@@ -44,6 +68,12 @@ type ScalarSetter struct {
 
 	// Count is the number of fields parameterized by the setter
 	Count int
+
+	// Description is the comment adjacent to the fields parameterized by the setter
+	Description string
+
+	// FieldPaths are the fields parameterized by the setter
+	FieldPaths []FieldPath
 }
 
 // ArraySetter stores name, values and count of the array setter
@@ -54,8 +84,26 @@ type ArraySetter struct {
 	// Values are the values of the field parameterized by the setter
 	Values []string
 
+	// ItemType is the data type for the values
+	ItemType string
+
 	// Count is the number of fields parameterized by the setter
 	Count int
+
+	// Description is the comment adjacent to the fields parameterized by the setter
+	Description string
+
+	// FieldPaths are the fields parameterized by the setter
+	FieldPaths []FieldPath
+}
+
+// FieldPath references a field parameterized by a setter
+type FieldPath struct {
+	// File is the file path of the resource
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+
+	// Path is the path to the field; path elements are separated by '.'
+	Path string `yaml:"path" json:"path"`
 }
 
 // Result represents results of setter discovery
@@ -190,6 +238,7 @@ func New() ListSetters {
 
 // addKptfileSetters parses setters in fn config to ArraySetters or ScalarSetters
 func (ls *ListSetters) addKptfileSetters(s map[string]string) {
+	ls.kptfileSetters = s
 	for setterName, setterValue := range s {
 		v, err := getArraySetterValues(setterValue)
 		if err == nil {
@@ -204,7 +253,7 @@ func (ls *ListSetters) addKptfileSetters(s map[string]string) {
 func (ls *ListSetters) GetResults() []*Result {
 	var out []*Result
 	for _, v := range ls.ArraySetters {
-		out = append(out, &Result{Name: v.Name, Value: fmt.Sprintf("[%s]", strings.Join(v.Values, ", ")), Count: v.Count, Type: ArraySetterType})
+		out = append(out, &Result{Name: v.Name, Value: formatArrayValue(v.Values), Count: v.Count, Type: ArraySetterType})
 	}
 	for _, v := range ls.ScalarSetters {
 		out = append(out, &Result{Name: v.Name, Value: v.Value, Count: v.Count, Type: v.Type})
@@ -241,7 +290,7 @@ func (ls *ListSetters) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 			return nil, errors.Wrap(err)
 		}
 	}
-	return nodes, nil
+	return ls.writeOutput(nodes)
 }
 
 /*
@@ -257,6 +306,12 @@ func (ls *ListSetters) visitMapping(object *yaml.RNode, path string) error {
 			return nil
 		}
 
+		// record the description of the scalar setters from the comment of the key
+		if node.Value.YNode().Kind == yaml.ScalarNode {
+			ls.addDescription(node.Value.YNode().LineComment, node.Key.YNode().HeadComment)
+			return nil
+		}
+
 		// return if it is not a sequence node
 		if node.Value.YNode().Kind != yaml.SequenceNode {
 			return nil
@@ -269,8 +324,10 @@ func (ls *ListSetters) visitMapping(object *yaml.RNode, path string) error {
 
 		// extracts the values in sequence node to an array
 		var nodeValues []string
+		itemType := ScalarSetterDefaultType
 		for _, values := range elements {
 			nodeValues = append(nodeValues, values.YNode().Value)
+			itemType = strings.TrimPrefix(values.YNode().Tag, "!!")
 		}
 		sort.Strings(nodeValues)
 
@@ -294,6 +351,16 @@ func (ls *ListSetters) visitMapping(object *yaml.RNode, path string) error {
 		} else {
 			ls.ArraySetters[setterName] = &ArraySetter{Name: setterName, Values: nodeValues, Count: 1}
 		}
+		as := ls.ArraySetters[setterName]
+		if len(elements) > 0 {
+			as.ItemType = itemType
+		}
+		if as.Description == "" {
+			as.Description = description(node.Key.YNode().HeadComment)
+		}
+		fieldPath := FieldPath{File: ls.filePath, Path: strings.TrimPrefix(fmt.Sprintf("%s.%s", path, node.Key.YNode().Value), ".")}
+		as.FieldPaths = append(as.FieldPaths, fieldPath)
+		ls.addUsage(setterName, fieldPath, formatArrayValue(nodeValues))
 		return nil
 	})
 }
@@ -322,6 +389,9 @@ func (ls *ListSetters) visitScalar(object *yaml.RNode, path string) error {
 		return nil
 	}
 	currentSetterValues := currentSetterValues(setterPattern, object.YNode().Value)
+	if len(currentSetterValues) == 0 {
+		ls.addUnresolved(setterPattern, object.YNode().Value, FieldPath{File: ls.filePath, Path: strings.TrimPrefix(path, ".")})
+	}
 	// data type for the current value
 	valueType := strings.TrimPrefix(object.YNode().Tag, "!!")
 
@@ -338,11 +408,48 @@ func (ls *ListSetters) visitScalar(object *yaml.RNode, path string) error {
 		} else {
 			ls.ScalarSetters[setterName] = &ScalarSetter{Name: setterName, Value: setterValue, Type: valueType, Count: 1}
 		}
-
+		ss := ls.ScalarSetters[setterName]
+		if ss.Description == "" {
+			ss.Description = ls.descriptions[setterName]
+		}
+		fieldPath := FieldPath{File: ls.filePath, Path: strings.TrimPrefix(path, ".")}
+		ss.FieldPaths = append(ss.FieldPaths, fieldPath)
+		ls.addUsage(setterName, fieldPath, setterValue)
 	}
 	return nil
 }
 
+// addDescription records the head comment of a field as the description
+// of the setters in the line comment of the field value
+func (ls *ListSetters) addDescription(lineComment, headComment string) {
+	setterPattern := extractSetterPattern(lineComment)
+	desc := description(headComment)
+	if setterPattern == "" || desc == "" {
+		return
+	}
+	if ls.descriptions == nil {
+		ls.descriptions = make(map[string]string)
+	}
+	for _, setter := range unresolvedSetters(setterPattern) {
+		if _, ok := ls.descriptions[clean(setter)]; !ok {
+			ls.descriptions[clean(setter)] = desc
+		}
+	}
+}
+
+// description converts the head comment of a field to a description
+// e.g. "# number of replicas\n# of the frontend" returns "number of replicas of the frontend"
+func description(headComment string) string {
+	var lines []string
+	for _, line := range strings.Split(headComment, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
 // extractSetterPattern extracts the setter pattern from the line comment of the
 // yaml RNode. If the the line comment doesn't contain SetterCommentIdentifier
 // prefix, then it returns empty string
@@ -0,0 +1,264 @@
+package listsetters
+
+import (
+	"fmt"
+	"strconv"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	// OutputSetters writes a local-config Setters resource describing the setters
+	OutputSetters = "setters"
+
+	// OutputJSONSchema writes a local-config SettersSchema resource holding
+	// the JSON Schema of the setters
+	OutputJSONSchema = "json-schema"
+
+	OutputAPIVersion  = "fn.kpt.dev/v1alpha1"
+	SettersKind       = "Setters"
+	SettersSchemaKind = "SettersSchema"
+
+	outputKey     = "output"
+	outputPathKey = "output-path"
+	validateKey   = "validate"
+
+	localConfigAnnotation = "config.kubernetes.io/local-config"
+	jsonSchemaDraft       = "http://json-schema.org/draft-07/schema#"
+)
+
+// defaultOutputPaths holds the default file path of the output resources
+var defaultOutputPaths = map[string]string{
+	OutputSetters:    "setters.yaml",
+	OutputJSONSchema: "setters-schema.yaml",
+}
+
+// SetterInfo describes a setter in the Setters resource
+type SetterInfo struct {
+	// Name is the name of the setter
+	Name string `yaml:"name"`
+
+	// Type is the JSON Schema type of the setter value
+	Type string `yaml:"type"`
+
+	// Value is the current value of the setter
+	Value interface{} `yaml:"value"`
+
+	// Items describes the values of array setters
+	Items *ItemsInfo `yaml:"items,omitempty"`
+
+	// Description is the comment adjacent to the fields parameterized by the setter
+	Description string `yaml:"description,omitempty"`
+
+	// FieldPaths are the fields parameterized by the setter
+	FieldPaths []FieldPath `yaml:"fieldPaths,omitempty"`
+}
+
+// ItemsInfo describes the values of array setters
+type ItemsInfo struct {
+	// Type is the JSON Schema type of the values
+	Type string `yaml:"type"`
+}
+
+// Decode decodes the optional ConfigMap functionConfig into ListSetters
+// e.g.
+//
+//	data:
+//	  validate: "true"
+//	  output: json-schema
+//	  output-path: schemas/setters.yaml
+func Decode(rn *yaml.RNode, ls *ListSetters) error {
+	if rn == nil || rn.IsNilOrEmpty() {
+		return nil
+	}
+	data := rn.GetDataMap()
+	if v, ok := data[validateKey]; ok {
+		validate, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q, must be a boolean", validateKey, v)
+		}
+		ls.Validate = validate
+	}
+	ls.Output = data[outputKey]
+	ls.OutputPath = data[outputPathKey]
+	if ls.Output == "" {
+		if ls.OutputPath != "" {
+			return fmt.Errorf("%q requires %q to be set", outputPathKey, outputKey)
+		}
+		return nil
+	}
+	if _, ok := defaultOutputPaths[ls.Output]; !ok {
+		return fmt.Errorf("invalid %s %q, must be one of [%s, %s]", outputKey, ls.Output, OutputSetters, OutputJSONSchema)
+	}
+	if ls.OutputPath == "" {
+		ls.OutputPath = defaultOutputPaths[ls.Output]
+	}
+	return nil
+}
+
+// GetSetterInfos returns the sorted descriptions of all setters
+func (ls *ListSetters) GetSetterInfos() []SetterInfo {
+	var out []SetterInfo
+	for _, r := range ls.GetResults() {
+		if as, ok := ls.ArraySetters[r.Name]; ok && r.Type == ArraySetterType {
+			itemType := as.ItemType
+			if itemType == "" {
+				itemType = ScalarSetterDefaultType
+			}
+			values := make([]interface{}, len(as.Values))
+			for i, v := range as.Values {
+				values[i] = typedValue(itemType, v)
+			}
+			out = append(out, SetterInfo{
+				Name:        as.Name,
+				Type:        "array",
+				Value:       values,
+				Items:       &ItemsInfo{Type: jsonSchemaType(itemType)},
+				Description: as.Description,
+				FieldPaths:  as.FieldPaths,
+			})
+			continue
+		}
+		ss := ls.ScalarSetters[r.Name]
+		out = append(out, SetterInfo{
+			Name:        ss.Name,
+			Type:        jsonSchemaType(ss.Type),
+			Value:       typedValue(ss.Type, ss.Value),
+			Description: ss.Description,
+			FieldPaths:  ss.FieldPaths,
+		})
+	}
+	return out
+}
+
+// writeOutput adds the output resource to the nodes, replacing
+// the output resource of a previous invocation if it exists
+func (ls *ListSetters) writeOutput(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	var kind string
+	var body map[string]interface{}
+	switch ls.Output {
+	case OutputSetters:
+		kind = SettersKind
+		body = map[string]interface{}{"setters": ls.GetSetterInfos()}
+	case OutputJSONSchema:
+		kind = SettersSchemaKind
+		body = map[string]interface{}{"schema": ls.jsonSchema()}
+	default:
+		return nodes, nil
+	}
+
+	out, err := newOutputResource(kind, ls.OutputPath, body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		if nodes[i].GetApiVersion() == OutputAPIVersion && nodes[i].GetKind() == kind {
+			nodes[i] = out
+			return nodes, nil
+		}
+	}
+	return append(nodes, out), nil
+}
+
+/*
+jsonSchema returns the JSON Schema describing the setters as inputs of the package
+e.g. for the setter `replicas` with current value 4 and the setter `env` with
+current values [dev, stage], the schema is
+
+	$schema: http://json-schema.org/draft-07/schema#
+	type: object
+	properties:
+	  env:
+	    type: array
+	    items:
+	      type: string
+	    default: [dev, stage]
+	  replicas:
+	    type: integer
+	    default: 4
+*/
+func (ls *ListSetters) jsonSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, info := range ls.GetSetterInfos() {
+		property := map[string]interface{}{
+			"type":    info.Type,
+			"default": info.Value,
+		}
+		if info.Items != nil {
+			property["items"] = info.Items
+		}
+		if info.Description != "" {
+			property["description"] = info.Description
+		}
+		if len(info.FieldPaths) > 0 {
+			property["x-kpt-field-paths"] = info.FieldPaths
+		}
+		properties[info.Name] = property
+	}
+	return map[string]interface{}{
+		"$schema":    jsonSchemaDraft,
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// newOutputResource creates the local-config resource of input kind
+func newOutputResource(kind, path string, body map[string]interface{}) (*yaml.RNode, error) {
+	res := map[string]interface{}{
+		"apiVersion": OutputAPIVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name": "setters",
+			"annotations": map[string]string{
+				localConfigAnnotation:        "true",
+				kioutil.PathAnnotation:       path,
+				kioutil.LegacyPathAnnotation: path,
+			},
+		},
+	}
+	for k, v := range body {
+		res[k] = v
+	}
+	b, err := yaml.Marshal(res)
+	if err != nil {
+		return nil, errors.WrapPrefixf(err, "unable to write %s", kind)
+	}
+	rn, err := yaml.Parse(string(b))
+	return rn, errors.WrapPrefixf(err, "unable to write %s", kind)
+}
+
+// jsonSchemaType converts the yaml tag of a value to JSON Schema type
+func jsonSchemaType(yamlType string) string {
+	switch yamlType {
+	case "int":
+		return "integer"
+	case "float":
+		return "number"
+	case "bool":
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// typedValue converts the value to its Go type as per the yaml tag,
+// the value is returned as is if it can't be converted
+func typedValue(yamlType, value string) interface{} {
+	switch yamlType {
+	case "int":
+		if v, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return v
+		}
+	case "float":
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case "bool":
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+	return value
+}
@@ -0,0 +1,167 @@
+package listsetters
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const outputInput = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx # kpt-set: ${app}
+spec:
+  # number of nginx replicas
+  replicas: 4 # kpt-set: ${replicas}
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.16.1 # kpt-set: ${app}:${tag}
+          args: # kpt-set: ${args}
+            - --debug
+            - --verbose
+`
+
+func TestListSettersOutput(t *testing.T) {
+	var tests = []struct {
+		name     string
+		config   string
+		expected string
+		errMsg   string
+	}{
+		{
+			name: "setters resource",
+			config: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: list-setters-fn-config
+data:
+  output: setters
+`,
+			expected: `apiVersion: fn.kpt.dev/v1alpha1
+kind: Setters
+metadata:
+  annotations:
+    config.kubernetes.io/local-config: "true"
+    config.kubernetes.io/path: setters.yaml
+    internal.config.kubernetes.io/path: setters.yaml
+  name: setters
+setters:
+- name: app
+  type: string
+  value: nginx
+  fieldPaths:
+  - path: metadata.name
+  - path: spec.template.spec.containers[0].image
+- name: args
+  type: array
+  value:
+  - --debug
+  - --verbose
+  items:
+    type: string
+  fieldPaths:
+  - path: spec.template.spec.containers[0].args
+- name: replicas
+  type: integer
+  value: 4
+  description: number of nginx replicas
+  fieldPaths:
+  - path: spec.replicas
+- name: tag
+  type: string
+  value: 1.16.1
+  fieldPaths:
+  - path: spec.template.spec.containers[0].image
+`,
+		},
+		{
+			name: "json schema resource",
+			config: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: list-setters-fn-config
+data:
+  output: json-schema
+  output-path: schemas/setters.yaml
+`,
+			expected: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SettersSchema
+metadata:
+  annotations:
+    config.kubernetes.io/local-config: "true"
+    config.kubernetes.io/path: schemas/setters.yaml
+    internal.config.kubernetes.io/path: schemas/setters.yaml
+  name: setters
+schema:
+  $schema: http://json-schema.org/draft-07/schema#
+  properties:
+    app:
+      default: nginx
+      type: string
+      x-kpt-field-paths:
+      - path: metadata.name
+      - path: spec.template.spec.containers[0].image
+    args:
+      default:
+      - --debug
+      - --verbose
+      items:
+        type: string
+      type: array
+      x-kpt-field-paths:
+      - path: spec.template.spec.containers[0].args
+    replicas:
+      default: 4
+      description: number of nginx replicas
+      type: integer
+      x-kpt-field-paths:
+      - path: spec.replicas
+    tag:
+      default: 1.16.1
+      type: string
+      x-kpt-field-paths:
+      - path: spec.template.spec.containers[0].image
+  type: object
+`,
+		},
+		{
+			name: "invalid output",
+			config: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: list-setters-fn-config
+data:
+  output: xml
+`,
+			errMsg: `invalid output "xml", must be one of [setters, json-schema]`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			ls := New()
+			err := Decode(yaml.MustParse(test.config), &ls)
+			if test.errMsg != "" {
+				require.EqualError(err, test.errMsg)
+				return
+			}
+			require.NoError(err)
+
+			nodes, err := (&kio.ByteReader{Reader: strings.NewReader(outputInput), OmitReaderAnnotations: true}).Read()
+			require.NoError(err)
+			nodes, err = ls.Filter(nodes)
+			require.NoError(err)
+			require.Len(nodes, 2)
+
+			var out bytes.Buffer
+			require.NoError(kio.ByteWriter{Writer: &out}.Write(nodes[1:]))
+			require.Equal(test.expected, out.String())
+		})
+	}
+}
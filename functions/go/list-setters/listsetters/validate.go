@@ -0,0 +1,164 @@
+package listsetters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// IssueConflict is reported for the fields whose values of the same
+	// setter disagree with each other
+	IssueConflict = "conflict"
+
+	// IssueKptfileMismatch is reported for the fields whose setter value
+	// disagrees with the value declared in the Kptfile
+	IssueKptfileMismatch = "kptfile-mismatch"
+
+	// IssueUnused is reported for the setters declared in the Kptfile
+	// which don't parameterize any field
+	IssueUnused = "unused"
+
+	// IssueUnresolved is reported for the setter comments whose pattern
+	// can't be resolved from the field value
+	IssueUnresolved = "unresolved"
+)
+
+// Issue is a problem found while validating the setters
+type Issue struct {
+	// Type is the type of the issue
+	Type string
+
+	// Setter is the name of the setter, empty for IssueUnresolved
+	Setter string
+
+	// Message is a human readable message
+	Message string
+
+	// FieldPath is the field with the issue, nil for IssueUnused
+	FieldPath *FieldPath
+}
+
+// usage is a field parameterized by a setter
+type usage struct {
+	FieldPath
+
+	// value is the value of the setter derived from the field value
+	value string
+}
+
+// addUsage records the field parameterized by the setter
+func (ls *ListSetters) addUsage(setterName string, fieldPath FieldPath, value string) {
+	if ls.usages == nil {
+		ls.usages = make(map[string][]usage)
+	}
+	ls.usages[setterName] = append(ls.usages[setterName], usage{FieldPath: fieldPath, value: value})
+}
+
+// addUnresolved records the setter comment which can't be resolved from the field value
+func (ls *ListSetters) addUnresolved(setterPattern, value string, fieldPath FieldPath) {
+	ls.unresolved = append(ls.unresolved, &Issue{
+		Type:      IssueUnresolved,
+		Message:   fmt.Sprintf("setter comment %q can't be resolved from the value %q", SetterCommentIdentifier+setterPattern, value),
+		FieldPath: &fieldPath,
+	})
+}
+
+/*
+GetIssues returns the issues of the discovered setters sorted by setter name
+  - IssueConflict for each field of a setter whose fields have different values
+    e.g. ${env} resolves to dev in one field and prod in another
+  - IssueKptfileMismatch for each field whose value differs from the apply-setters
+    config in the Kptfile
+  - IssueUnused for each setter declared in the Kptfile which is not used
+  - IssueUnresolved for each setter comment which can't be resolved from the field value
+*/
+func (ls *ListSetters) GetIssues() []*Issue {
+	var issues []*Issue
+	for _, r := range ls.GetResults() {
+		usages := ls.usages[r.Name]
+		kfValue, declared := ls.kptfileSetters[r.Name]
+		if r.Type == ArraySetterType && declared {
+			if values, err := getArraySetterValues(kfValue); err == nil {
+				kfValue = formatArrayValue(sortedCopy(values))
+			}
+		}
+		if declared && len(usages) == 0 {
+			issues = append(issues, &Issue{
+				Type:    IssueUnused,
+				Setter:  r.Name,
+				Message: fmt.Sprintf("setter %q is declared in the Kptfile but doesn't parameterize any field", r.Name),
+			})
+			continue
+		}
+
+		values := distinctValues(usages)
+		for i := range usages {
+			u := usages[i]
+			if len(values) > 1 {
+				issues = append(issues, &Issue{
+					Type:   IssueConflict,
+					Setter: r.Name,
+					Message: fmt.Sprintf("setter %q has value %q which conflicts with the values %s of other fields",
+						r.Name, u.value, quote(without(values, u.value))),
+					FieldPath: &u.FieldPath,
+				})
+			}
+			if declared && u.value != kfValue {
+				issues = append(issues, &Issue{
+					Type:   IssueKptfileMismatch,
+					Setter: r.Name,
+					Message: fmt.Sprintf("setter %q has value %q which doesn't match the value %q declared in the Kptfile",
+						r.Name, u.value, kfValue),
+					FieldPath: &u.FieldPath,
+				})
+			}
+		}
+	}
+	return append(issues, ls.unresolved...)
+}
+
+// distinctValues returns the sorted distinct values of the usages
+func distinctValues(usages []usage) []string {
+	seen := map[string]bool{}
+	var values []string
+	for _, u := range usages {
+		if !seen[u.value] {
+			seen[u.value] = true
+			values = append(values, u.value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// without returns the values except the input value
+func without(values []string, value string) []string {
+	var out []string
+	for _, v := range values {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// quote returns the quoted values as a list e.g. ["dev", "prod"]
+func quote(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("[%s]", strings.Join(quoted, ", "))
+}
+
+// formatArrayValue formats the values of an array setter e.g. [dev, stage]
+func formatArrayValue(values []string) string {
+	return fmt.Sprintf("[%s]", strings.Join(values, ", "))
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,126 @@
+package listsetters
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+)
+
+func TestGetIssues(t *testing.T) {
+	var tests = []struct {
+		name        string
+		resourceMap map[string]string
+		expected    []*Issue
+	}{
+		{
+			name: "consistent setters",
+			resourceMap: map[string]string{"Kptfile": `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: test
+pipeline:
+  mutators:
+    - image: ghcr.io/kptdev/krm-functions-catalog/apply-setters:v0.2
+      configMap:
+        app: my-app
+        envs: |
+          - stage
+          - dev
+`, "test.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: my-app # kpt-set: ${app}
+environments: # kpt-set: ${envs}
+  - dev
+  - stage
+`},
+		},
+		{
+			name: "conflicting values, Kptfile mismatch and unused setters",
+			resourceMap: map[string]string{"Kptfile": `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: test
+pipeline:
+  mutators:
+    - image: ghcr.io/kptdev/krm-functions-catalog/apply-setters:v0.2
+      configMap:
+        env: dev
+        region: us-east1
+`, "dev.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-dev-cm # kpt-set: my-${env}-cm
+`, "prod.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-prod-cm # kpt-set: my-${env}-cm
+`},
+			expected: []*Issue{
+				{
+					Type:      IssueConflict,
+					Setter:    "env",
+					Message:   `setter "env" has value "dev" which conflicts with the values ["prod"] of other fields`,
+					FieldPath: &FieldPath{File: "dev.yaml", Path: "metadata.name"},
+				},
+				{
+					Type:      IssueConflict,
+					Setter:    "env",
+					Message:   `setter "env" has value "prod" which conflicts with the values ["dev"] of other fields`,
+					FieldPath: &FieldPath{File: "prod.yaml", Path: "metadata.name"},
+				},
+				{
+					Type:      IssueKptfileMismatch,
+					Setter:    "env",
+					Message:   `setter "env" has value "prod" which doesn't match the value "dev" declared in the Kptfile`,
+					FieldPath: &FieldPath{File: "prod.yaml", Path: "metadata.name"},
+				},
+				{
+					Type:    IssueUnused,
+					Setter:  "region",
+					Message: `setter "region" is declared in the Kptfile but doesn't parameterize any field`,
+				},
+			},
+		},
+		{
+			name: "unresolved setter comment",
+			resourceMap: map[string]string{"test.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-cm # kpt-set: app-${name}
+`},
+			expected: []*Issue{
+				{
+					Type:      IssueUnresolved,
+					Message:   `setter comment "# kpt-set: app-${name}" can't be resolved from the value "my-cm"`,
+					FieldPath: &FieldPath{File: "test.yaml", Path: "metadata.name"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			pkgDir := setupInputs(t, test.resourceMap)
+			//nolint:errcheck
+			defer os.RemoveAll(pkgDir)
+
+			ls := New()
+			ls.Validate = true
+			inout := &kio.LocalPackageReadWriter{
+				PackagePath:     pkgDir,
+				NoDeleteFiles:   true,
+				PackageFileName: "Kptfile",
+				MatchFilesGlob:  append(kio.DefaultMatch, "Kptfile"),
+			}
+			err := kio.Pipeline{
+				Inputs:  []kio.Reader{inout},
+				Filters: []kio.Filter{&ls},
+			}.Execute()
+			require.NoError(err)
+			require.Equal(test.expected, ls.GetIssues())
+		})
+	}
+}
@@ -39,19 +39,29 @@ func (lsp *ListSettersProcessor) Process(resourceList *framework.ResourceList) e
 		return err
 	}
 	resourceList.Results = results
+	if results.ExitCode() != 0 {
+		return results
+	}
 	return nil
 }
 
 func run(resourceList *framework.ResourceList) (framework.Results, error) {
 	ls := listsetters.New()
-	_, err := ls.Filter(resourceList.Items)
+	if err := listsetters.Decode(resourceList.FunctionConfig, &ls); err != nil {
+		return nil, err
+	}
+	items, err := ls.Filter(resourceList.Items)
 	if err != nil {
 		return nil, err
 	}
+	resourceList.Items = items
 	resultItems, err := resultsToItems(ls)
 	if err != nil {
 		return nil, err
 	}
+	if ls.Validate {
+		resultItems = append(resultItems, issuesToItems(ls.GetIssues())...)
+	}
 	return resultItems, nil
 }
 
@@ -68,9 +78,36 @@ func resultsToItems(sr listsetters.ListSetters) (framework.Results, error) {
 			Message: r.String(),
 		})
 	}
+	if sr.Output != "" {
+		results = append(results, &framework.Result{
+			Message: fmt.Sprintf("Wrote %s output to %s", sr.Output, sr.OutputPath),
+			File:    &framework.File{Path: sr.OutputPath},
+		})
+	}
 	return results, nil
 }
 
+// issuesToItems converts the listsetters validation issues to
+// equivalent items, unused setters are reported as warnings
+func issuesToItems(issues []*listsetters.Issue) framework.Results {
+	var results framework.Results
+	for _, issue := range issues {
+		result := &framework.Result{
+			Message:  issue.Message,
+			Severity: framework.Error,
+		}
+		if issue.Type == listsetters.IssueUnused {
+			result.Severity = framework.Warning
+		}
+		if issue.FieldPath != nil {
+			result.Field = &framework.Field{Path: issue.FieldPath.Path}
+			result.File = &framework.File{Path: issue.FieldPath.File}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 // getErrorItem returns the item for an error message
 func getErrorItem(errMsg string, severity framework.Severity) framework.Results {
 	return framework.Results{
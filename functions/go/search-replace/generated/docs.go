@@ -37,6 +37,26 @@ Matchers:
   Match by file path expression. Input must be OS-agnostic Slash(/) separated file path
   relative to the directory on which the function is invoked. Please note that the
   file path expressions are not regular expressions.
+  
+  by-kind
+  Match only the fields of resources with the given kind, e.g. Deployment.
+  
+  by-api-version
+  Match only the fields of resources with the given apiVersion, e.g. apps/v1.
+  
+  by-name
+  Match only the fields of resources with the given metadata.name.
+  
+  by-namespace
+  Match only the fields of resources with the given metadata.namespace.
+  
+  by-label-selector
+  Match only the fields of resources whose labels match the label selector, e.g.
+  app=nginx,tier!=cache. The syntax is the same as the kubectl --selector flag.
+
+The resource matchers ` + "`" + `by-kind` + "`" + `, ` + "`" + `by-api-version` + "`" + `, ` + "`" + `by-name` + "`" + `, ` + "`" + `by-namespace` + "`" + ` and
+` + "`" + `by-label-selector` + "`" + ` select the resources to search and are combined with the field
+matchers, all the provided matchers must match.
 
 Mutators:
 
@@ -47,6 +67,24 @@ Mutators:
   put-comment
   Set or update the line comment for matching fields. Input can be a pattern for
   which the numbered capture groups are resolved using --by-value-regex input.
+  
+  put-yaml
+  Set or replace the matching fields with a YAML snippet, e.g. a map or a list.
+  Fields of any type are matched using --by-path, only scalar fields are matched
+  using --by-value or --by-value-regex. The field is created if --by-path is an
+  absolute path and the field doesn't exist.
+  
+  append-yaml
+  Append a YAML snippet to the list fields matching --by-path. If the snippet is a
+  list, each of its elements is appended. The list is created if --by-path is an
+  absolute path and the field doesn't exist.
+  
+  delete
+  Delete the matching fields or list elements when set to "true". Fields of any
+  type are matched using --by-path, only scalar fields and list elements are matched
+  using --by-value or --by-value-regex.
+
+Only one of ` + "`" + `put-value` + "`" + `, ` + "`" + `put-yaml` + "`" + `, ` + "`" + `append-yaml` + "`" + ` and ` + "`" + `delete` + "`" + ` can be provided.
 
 We use ConfigMap to configure the ` + "`" + `search-replace` + "`" + ` function. The inputs are
 provided as key-value pairs using ` + "`" + `data` + "`" + ` field.
@@ -68,14 +106,72 @@ Alternatively, data can be passed as key-value pairs in the CLI
 
   $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- 'by-path=metadata.name' 'put-value=the-deployment'
 
+Multiple rules:
+
+To perform several operations in a single step, a ` + "`" + `SearchReplaceRules` + "`" + ` resource
+can be provided instead of the ConfigMap. Each rule holds the same matchers and
+mutators as the ConfigMap ` + "`" + `data` + "`" + `. The rules are applied in order to each resource
+in a single pass over the resources, so a rule sees the changes made by the rules
+before it. The results are grouped by rule.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: SearchReplaceRules
+  metadata:
+    name: search-replace-fn-config
+  rules:
+    - name: scale-frontend
+      on-no-match: error
+      by-kind: Deployment
+      by-name: frontend
+      by-path: spec.replicas
+      put-value: 5
+    - name: rename-image
+      by-value-regex: nginx:(.*)
+      put-value: ghcr.io/nginx:${1}
+
+- ` + "`" + `name` + "`" + `: name of the rule used in the results, defaults to ` + "`" + `rule-<n>` + "`" + ` where ` + "`" + `n` + "`" + ` is
+  the position of the rule starting from 1.
+- ` + "`" + `on-no-match` + "`" + `: reported if the rule doesn't match any field, one of ` + "`" + `ignore` + "`" + `
+  (default), ` + "`" + `warning` + "`" + ` or ` + "`" + `error` + "`" + `. The function fails if a rule set to ` + "`" + `error` + "`" + `
+  doesn't match any field.
+
+Query:
+
+  query
+  Evaluate an expression on each resource for a read-only search. The resources
+  and fields are not changed. The result of the query lists each matching resource
+  with its reference, file path and the values returned by the query, and the number
+  of matching resources per kind.
+  
+  query-language
+  Language of the query, one of "cel" (default) or "jsonpath".
+
+A CEL query is evaluated with the resource bound to the ` + "`" + `object` + "`" + ` variable. The
+resource matches if the query returns ` + "`" + `true` + "`" + `, a non-empty list or any other
+non-null value. The list elements and values are reported as the matched values.
+Selecting a missing field or map key, e.g. ` + "`" + `object.spec.replicas` + "`" + ` on a Service,
+doesn't match the resource, any other evaluation error fails the function.
+
+A JSONPath query uses the ` + "`" + `kubectl` + "`" + ` JSONPath syntax, the surrounding braces are
+optional, e.g. ` + "`" + `.metadata.labels.app\.kubernetes\.io/name` + "`" + `. The resource matches
+if the query returns any value.
+
+` + "`" + `query` + "`" + ` can be combined with ` + "`" + `by-file-path` + "`" + ` and the resource matchers, e.g. ` + "`" + `by-kind` + "`" + `,
+but not with the field matchers and mutators.
+
 ### Field path patterns
 
 ` + "`" + `by-path` + "`" + ` matcher supports the following patterns:
 
-| Special Terms | Meaning                     |
-| ------------- | --------------------------- |
-| ` + "`" + `*` + "`" + `           | matches exactly one field   |
-| ` + "`" + `**` + "`" + `          | matches zero or more fields |
+| Special Terms | Meaning                                                      |
+| ------------- | ------------------------------------------------------------ |
+| ` + "`" + `*` + "`" + `           | matches exactly one field                                    |
+| ` + "`" + `**` + "`" + `          | matches zero or more fields                                  |
+| ` + "`" + `[n]` + "`" + `         | matches the list element at index ` + "`" + `n` + "`" + `                        |
+| ` + "`" + `[*]` + "`" + `         | matches all the list elements                                |
+| ` + "`" + `[k=v]` + "`" + `       | matches the list elements whose field ` + "`" + `k` + "`" + ` has the value ` + "`" + `v` + "`" + `  |
+| ` + "`" + `[=v]` + "`" + `        | matches the scalar list elements with the value ` + "`" + `v` + "`" + `          |
+| ` + "`" + `[+]` + "`" + `         | appends a new list element, only with ` + "`" + `put-value` + "`" + `/` + "`" + `put-yaml` + "`" + ` |
 
   a.b.c
   
@@ -132,6 +228,25 @@ Alternatively, data can be passed as key-value pairs in the CLI
     - c: thing2 # MATCHES
       f: thingamabob
 
+  a.b[name=nginx].c
+  
+  a:
+    b:
+    - name: nginx
+      c: thing0 # MATCHES
+    - name: sidecar
+      c: thing1
+
+When ` + "`" + `put-value` + "`" + ` or ` + "`" + `put-yaml` + "`" + ` is provided with an absolute ` + "`" + `by-path` + "`" + `, i.e. a path
+without ` + "`" + `*` + "`" + ` and ` + "`" + `**` + "`" + `, the value is put directly at the path:
+
+- missing fields are created.
+- a missing list element selected by ` + "`" + `[k=v]` + "`" + ` is appended to the list with the field ` + "`" + `k` + "`" + `.
+- a list element selected by ` + "`" + `[n]` + "`" + ` is never created, the resource is skipped if the
+  index is out of range.
+- ` + "`" + `[+]` + "`" + ` appends a new element to the list, e.g. ` + "`" + `a.b[+]` + "`" + ` with ` + "`" + `put-value: foo` + "`" + ` appends
+  ` + "`" + `foo` + "`" + ` to the list ` + "`" + `a.b` + "`" + `, and ` + "`" + `a.b[+].c` + "`" + ` with ` + "`" + `put-value: foo` + "`" + ` appends ` + "`" + `{c: foo}` + "`" + `.
+
 ### File path patterns
 
 ` + "`" + `by-file-path` + "`" + ` matcher supports the following special terms in the patterns:
@@ -206,6 +321,24 @@ var SearchReplaceExamples = `
   # Set namespaces for all resources to "bookstore", even namespace is not set on a resource:
   $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-path='metadata.namespace' put-value='bookstore'
 
+  # Set the image of the container named "nginx" without matching the other containers:
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-path='spec.template.spec.containers[name=nginx].image' put-value='nginx:1.21'
+
+  # Append an argument to the args of the container named "nginx":
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-path='spec.template.spec.containers[name=nginx].args[+]' put-value='--debug'
+
+  # Scale only the Deployment named "frontend" to 5 replicas:
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-kind=Deployment by-name=frontend by-path='spec.replicas' put-value=5
+
+  # Matches fields with value "nginx" in resources labeled "app=nginx" in the "prod" namespace:
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-namespace=prod by-label-selector='app=nginx' by-value=nginx
+
+  # List the Deployments with more than 3 replicas:
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- by-kind=Deployment query='object.spec.replicas > 3'
+
+  # List the images of all the containers:
+  $ kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest -- query='.spec.template.spec.containers[*].image' query-language=jsonpath
+
   # Update the setter value "project-id" to value "new-project" in all "setters.yaml" files in the current directory tree:
   kpt fn eval --image ghcr.io/kptdev/krm-functions-catalog/search-replace:latest --include-meta-resources -- \
   by-value=project-id by-file-path='**/setters.yaml' put-value=new-project
@@ -38,11 +38,17 @@ func (srp *SearchReplaceProcessor) Process(resourceList *framework.ResourceList)
 		return err
 	}
 	resourceList.Result.Items = items
+	if resourceList.Result.ExitCode() != 0 {
+		return resourceList.Result
+	}
 	return nil
 }
 
 // run resolves the function params from input ResourceList and runs the function on resources
 func run(resourceList *framework.ResourceList) ([]framework.ResultItem, error) {
+	if searchreplace.IsRules(resourceList.FunctionConfig) {
+		return runRules(resourceList)
+	}
 	sr, err := getSearchReplaceParams(resourceList.FunctionConfig)
 	if err != nil {
 		return nil, err
@@ -56,6 +62,40 @@ func run(resourceList *framework.ResourceList) ([]framework.ResultItem, error) {
 	return searchResultsToItems(sr), nil
 }
 
+// runRules applies the rules of SearchReplaceRules function config on resources,
+// the results are grouped by rule in the order of the rules
+func runRules(resourceList *framework.ResourceList) ([]framework.ResultItem, error) {
+	var srr searchreplace.SearchReplaceRules
+	if err := searchreplace.DecodeRules(resourceList.FunctionConfig, &srr); err != nil {
+		return nil, err
+	}
+
+	_, err := srr.Filter(resourceList.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []framework.ResultItem
+	for _, rule := range srr.Rules {
+		if len(rule.Results) == 0 {
+			item := framework.ResultItem{Message: fmt.Sprintf("rule %q: no matches", rule.Name)}
+			switch rule.OnNoMatch {
+			case searchreplace.OnNoMatchWarning:
+				item.Severity = framework.Warning
+			case searchreplace.OnNoMatchError:
+				item.Severity = framework.Error
+			}
+			items = append(items, item)
+			continue
+		}
+		for _, item := range searchResultsToItems(rule.SearchReplace) {
+			item.Message = fmt.Sprintf("rule %q: %s", rule.Name, item.Message)
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
 // getSearchReplaceParams retrieve the search parameters from input config
 func getSearchReplaceParams(fc *kyaml.RNode) (searchreplace.SearchReplace, error) {
 	var fcd searchreplace.SearchReplace
@@ -68,6 +108,9 @@ func getSearchReplaceParams(fc *kyaml.RNode) (searchreplace.SearchReplace, error
 // searchResultsToItems converts the Search and Replace results to
 // equivalent items([]framework.Item)
 func searchResultsToItems(sr searchreplace.SearchReplace) []framework.ResultItem {
+	if sr.Query != "" {
+		return queryResultsToItems(sr)
+	}
 	var items []framework.ResultItem
 	if len(sr.Results) == 0 {
 		items = append(items, framework.ResultItem{
@@ -77,7 +120,9 @@ func searchResultsToItems(sr searchreplace.SearchReplace) []framework.ResultItem
 	}
 	for _, res := range sr.Results {
 		var message string
-		if sr.PutComment != "" || sr.PutValue != "" {
+		if sr.Delete {
+			message = fmt.Sprintf("Deleted field with value %q", res.Value)
+		} else if sr.PutComment != "" || sr.PutValue != "" || sr.PutYAML != "" || sr.AppendYAML != "" {
 			message = fmt.Sprintf("Mutated field value to %q", res.Value)
 		} else {
 			message = fmt.Sprintf("Matched field value %q", res.Value)
@@ -92,6 +137,35 @@ func searchResultsToItems(sr searchreplace.SearchReplace) []framework.ResultItem
 	return items
 }
 
+// queryResultsToItems converts the query results to equivalent items with a
+// reference to each matching resource followed by the number of matches per kind
+func queryResultsToItems(sr searchreplace.SearchReplace) []framework.ResultItem {
+	var items []framework.ResultItem
+	if len(sr.QueryResults) == 0 {
+		items = append(items, framework.ResultItem{
+			Message: "no matches",
+		})
+		return items
+	}
+	for _, res := range sr.QueryResults {
+		message := "Matched resource"
+		if len(res.Values) > 0 {
+			message = fmt.Sprintf("Matched resource with values %q", res.Values)
+		}
+		items = append(items, framework.ResultItem{
+			Message:     message,
+			ResourceRef: res.ResourceRef,
+			File:        framework.File{Path: res.FilePath},
+		})
+	}
+	for _, kc := range sr.KindCounts() {
+		items = append(items, framework.ResultItem{
+			Message: fmt.Sprintf("Matched %d %s resource(s)", kc.Count, kc.Kind),
+		})
+	}
+	return items
+}
+
 // getErrorItem returns the item for input error message
 func getErrorItem(errMsg string) []framework.ResultItem {
 	return []framework.ResultItem{
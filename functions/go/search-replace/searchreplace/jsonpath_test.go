@@ -0,0 +1,54 @@
+package searchreplace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestEvalJSONPath checks that the JSONPath queries are evaluated like kubectl
+func TestEvalJSONPath(t *testing.T) {
+	object := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "web",
+			"labels": map[string]interface{}{
+				"app":                    "web",
+				"app.kubernetes.io/name": "shop",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"ports": []interface{}{
+				map[string]interface{}{"name": "http", "port": int64(80)},
+				map[string]interface{}{"name": "https", "port": int64(443)},
+			},
+		},
+	}
+	testcases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "field without braces", query: ".spec.replicas", expected: []string{"3"}},
+		{name: "escaped dots in key", query: `.metadata.labels.app\.kubernetes\.io/name`, expected: []string{"shop"}},
+		{name: "multiple expressions", query: "{.metadata.name}{.spec.replicas}", expected: []string{"web", "3"}},
+		{name: "range", query: "{range .spec.ports[*]}{.name}{end}", expected: []string{"http", "https"}},
+		{name: "filter", query: `{.spec.ports[?(@.port==443)].name}`, expected: []string{"https"}},
+		{name: "missing field", query: ".spec.template.spec"},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sr := &SearchReplace{Query: tc.query, QueryLanguage: QueryLanguageJSONPath}
+			if !assert.NoError(t, sr.compileQuery()) {
+				t.FailNow()
+			}
+			matched, values, err := sr.evalJSONPath(object)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, len(tc.expected) > 0, matched)
+			assert.Equal(t, tc.expected, values)
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package searchreplace
+
+var listPathCases = []test{
+	{
+		name: "put value in list element selected by key",
+		config: `
+data:
+  by-path: spec.template.spec.containers[name=nginx].image
+  put-value: nginx:1.21
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.20
+      - name: sidecar
+        image: envoy
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[name=nginx].image
+value: nginx:1.21
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+      - name: sidecar
+        image: envoy
+`,
+	},
+	{
+		name: "put value creates list element selected by key",
+		config: `
+data:
+  by-path: spec.template.spec.containers[name=sidecar].image
+  put-value: envoy
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[name=sidecar].image
+value: envoy
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoy
+`,
+	},
+	{
+		name: "append value to list",
+		config: `
+data:
+  by-path: spec.template.spec.containers[name=nginx].args[+]
+  put-value: --debug
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        args:
+        - --port=80
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[name=nginx].args[+]
+value: --debug
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        args:
+        - --port=80
+        - --debug
+`,
+	},
+	{
+		name: "append yaml element to list",
+		config: `
+data:
+  by-path: spec.template.spec.volumes[+]
+  put-yaml: |
+    name: cache
+    emptyDir: {}
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.volumes[+]
+value: {name: cache, emptyDir: {}}
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+      volumes:
+      - name: cache
+        emptyDir: {}
+`,
+	},
+	{
+		name: "put value by index",
+		config: `
+data:
+  by-path: spec.template.spec.containers[1].image
+  put-value: envoy:1.0
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx-service
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[1].image
+value: envoy:1.0
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoy:1.0
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx-service
+`,
+	},
+	{
+		name: "search by path pattern with key selector",
+		config: `
+data:
+  by-path: spec.**.containers[name=sidecar].*
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoy
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[1].name
+value: sidecar
+
+${filePath}
+fieldPath: spec.template.spec.containers[1].image
+value: envoy
+
+Matched 2 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoy
+`,
+	},
+	{
+		name: "delete list element selected by key",
+		config: `
+data:
+  by-path: spec.args[=--debug]
+  delete: 'true'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  args:
+  - --debug
+  - --port=80
+`,
+		out: `${filePath}
+fieldPath: spec.args[0]
+value: --debug
+
+Deleted 1 field(s)
+`,
+		expectedResources: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  args:
+  - --port=80
+`,
+	},
+	{
+		name: "append marker with search",
+		config: `
+data:
+  by-path: spec.args[+]
+  by-value: foo
+  put-value: bar
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `"[+]" in "by-path" can only be used in an absolute path with "put-value" or "put-yaml"`,
+	},
+}
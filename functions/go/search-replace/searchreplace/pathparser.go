@@ -1,9 +1,17 @@
 package searchreplace
 
 import (
+	"strconv"
 	"strings"
+
+	"github.com/kptdev/krm-functions-catalog/functions/go/internal/pathmatch"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
+// appendMarker is the list selector which appends a new element to the list e.g. args[+]
+const appendMarker = "+"
+
 // pathMatch checks if the traversed yaml path matches with the user input path
 // checks if user input path is valid
 func (sr *SearchReplace) pathMatch(yamlPath string) bool {
@@ -12,93 +20,185 @@ func (sr *SearchReplace) pathMatch(yamlPath string) bool {
 	}
 
 	// split elements of input by-path
-	patternElems := strings.Split(sr.ByPath, PathDelimiter)
+	patternElems := splitPath(sr.ByPath)
 
 	// split elements of traversed yamlPath
 	yamlPathElems := strings.Split(strings.TrimPrefix(yamlPath, PathDelimiter), PathDelimiter)
 
-	// match input by-path with traversed path
-	return backTrackMatch(yamlPathElems, patternElems)
+	// match input by-path with traversed path, list elements selected by key
+	// are matched by looking up the traversed element in the resource
+	return pathmatch.MatchElems(yamlPathElems, patternElems, func(i int, pattern string) bool {
+		if _, selector := splitPathElem(pattern); strings.Contains(selector, "=") {
+			return sr.selectorMatch(yamlPathElems[:i+1], pattern)
+		}
+		return pathmatch.ElementMatch(yamlPathElems[i], pattern)
+	})
 }
 
-// backTrackMatch matches the traversed yamlPathElems with input(from by-path) patternElems
-// * matches any element, ** matches 0 or more elements, array elements are split and matched
-// refer to pathparser_test.go
-func backTrackMatch(yamlPathElems, patternElems []string) bool {
-	// this is a dynamic programming problem
-	// aim is to check if path array matches pattern array as per above rules
-	yamlPathElemsLen, patternElemsLen := len(yamlPathElems), len(patternElems)
-
-	// initialize a 2d boolean matrix to memorize results
-	// dp[i][j] stores the result, if yamlPath subarray of length i matches
-	// pattern subarray of length j
-	dp := make([][]bool, yamlPathElemsLen+1)
-	for i := range dp {
-		dp[i] = make([]bool, patternElemsLen+1)
-	}
-	dp[0][0] = true
-
-	// edge case 1: when pattern is empty, yamlPath of length grater than 0 doesn't match
-	for i := 1; i < yamlPathElemsLen+1; i++ {
-		dp[i][0] = false
-	}
-
-	// edge case 2: if yamlPath is empty, carry forward the previous result if the pattern element
-	// is `**` as it matches 0 or more elements.
-	for j := 1; j < patternElemsLen+1; j++ {
-		if patternElems[j-1] == "**" {
-			dp[0][j] = dp[0][j-1]
-		}
+// selectorMatch matches the traversed list element with the pattern selecting the
+// element by key e.g. containers[name=nginx] matches containers[0] if the name of
+// the first container is nginx, [=foo] selects the scalar element foo
+func (sr *SearchReplace) selectorMatch(yamlPathElems []string, pattern string) bool {
+	if sr.object == nil {
+		return false
+	}
+	field, selector := splitPathElem(pattern)
+	elemField, index := splitPathElem(yamlPathElems[len(yamlPathElems)-1])
+	if field != "*" && field != elemField {
+		return false
+	}
+	if _, err := strconv.Atoi(index); err != nil {
+		return false
+	}
+	var parts []string
+	for _, elem := range yamlPathElems {
+		parts = append(parts, elemParts(elem)...)
+	}
+	node, err := sr.object.Pipe(yaml.Lookup(parts...))
+	if err != nil || node == nil {
+		return false
+	}
+	key, value, err := yaml.SplitIndexNameValue("[" + selector + "]")
+	if err != nil {
+		return false
+	}
+	if key == "" {
+		return node.YNode().Kind == yaml.ScalarNode && node.YNode().Value == value
+	}
+	if node.YNode().Kind != yaml.MappingNode {
+		return false
 	}
+	keyNode := node.Field(key)
+	return keyNode != nil && keyNode.Value.YNode().Value == value
+}
 
-	// fill rest of the matrix
-	for i := 1; i < yamlPathElemsLen+1; i++ {
-		for j := 1; j < patternElemsLen+1; j++ {
-			if patternElems[j-1] == "**" {
-				// `**` matches multiple elements, so carry forward the result from immediate
-				// neighbors, dp[i-1][j] match empty, dp[i][j-1] match multiple elements
-				dp[i][j] = dp[i][j-1] || dp[i-1][j]
-			} else if patternElems[j-1] == "*" || elementMatch(yamlPathElems[i-1], patternElems[j-1]) {
-				// if there is element match or `*` then get the result from previous diagonal element
-				dp[i][j] = dp[i-1][j-1]
-			}
+// splitPath splits the path into elements by the delimiter outside of brackets
+// e.g. a.b[name=app.kubernetes.io].c is split into [a, b[name=app.kubernetes.io], c]
+func splitPath(path string) []string {
+	var elems []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case string(c) == PathDelimiter && depth == 0:
+			elems = append(elems, path[start:i])
+			start = i + 1
 		}
 	}
+	return append(elems, path[start:])
+}
 
-	/*Example matrix for yamlPath = [a,a,b,c,e,b] and pattern [a,*,b,**,b]
-		  a	a	b	c	e	b
-		a	T	F	F	F	F	F
-	  * F	T	F	F	F	F
-		b	F	F	T	F	F	F
-	 ** F	F	T	T	T	T
-		b	F	F	F	F	F	T
-	*/
+// splitPathElem splits the path element into the field name and the list selector
+// e.g. containers[name=nginx] is split into containers and name=nginx
+func splitPathElem(elem string) (string, string) {
+	i := strings.Index(elem, "[")
+	if i < 0 || !strings.HasSuffix(elem, "]") {
+		return elem, ""
+	}
+	return elem[:i], elem[i+1 : len(elem)-1]
+}
 
-	return dp[yamlPathElemsLen][patternElemsLen]
+// elemParts converts the path element into the parts of a kyaml path lookup
+// e.g. containers[name=nginx] is converted to [containers, [name=nginx]],
+// args[0] to [args, 0] and args[+] to [args, +]
+func elemParts(elem string) []string {
+	field, selector := splitPathElem(elem)
+	var parts []string
+	if field != "" {
+		parts = append(parts, field)
+	}
+	switch {
+	case strings.Contains(selector, "="):
+		parts = append(parts, "["+selector+"]")
+	case selector != "":
+		parts = append(parts, selector)
+	}
+	return parts
 }
 
-// elementMatch matches single element with pattern for single element
-func elementMatch(elem, pattern string) bool {
-	// scalar field case `metadata` matches `metadata`
-	if elem == pattern {
-		return true
-	}
-	// array element e.g. a[*], *[*] and *[b] matches a[b]
-	if strings.Contains(elem, "[") {
-		elemParts := strings.Split(elem, "[")
-		patternParts := strings.Split(pattern, "[")
-		if patternParts[0] != "*" && elemParts[0] != patternParts[0] {
-			return false
+// lookupParts converts the path into the parts of a kyaml path lookup
+// e.g. a[name=nginx].b[0].c[+] is converted to [a, [name=nginx], b, 0, c, +]
+func lookupParts(path string) []string {
+	var parts []string
+	for _, elem := range splitPath(path) {
+		parts = append(parts, elemParts(elem)...)
+	}
+	return parts
+}
+
+// isIndex checks if the lookup part is a numeric list index
+func isIndex(part string) bool {
+	_, err := strconv.Atoi(part)
+	return err == nil
+}
+
+// indexesExist checks if the list elements selected by numeric index exist,
+// the elements selected by index are never created
+func indexesExist(object *yaml.RNode, parts []string) (bool, error) {
+	last := -1
+	for i, part := range parts {
+		if part == appendMarker {
+			break
+		}
+		if isIndex(part) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return true, nil
+	}
+	node, err := object.Pipe(yaml.Lookup(parts[:last+1]...))
+	if err != nil {
+		return false, errors.Wrap(err)
+	}
+	return node != nil, nil
+}
+
+// lookupCreatePath looks up the node at the path parts and creates the missing fields
+// and the list elements selected by key, the leaf node is created with the input kind,
+// a new mapping element is appended to the list for each append marker
+func lookupCreatePath(object *yaml.RNode, parts []string, kind yaml.Kind) (*yaml.RNode, error) {
+	node := object
+	for len(parts) > 0 {
+		i := 0
+		for i < len(parts) && parts[i] != appendMarker {
+			i++
+		}
+		segmentKind := kind
+		if i < len(parts) {
+			segmentKind = yaml.SequenceNode
+		}
+		if i > 0 {
+			next, err := node.Pipe(yaml.LookupCreate(segmentKind, parts[:i]...))
+			if err != nil {
+				return nil, errors.Wrap(err)
+			}
+			if next == nil {
+				return nil, nil
+			}
+			node = next
+		}
+		if i == len(parts) {
+			break
+		}
+		if node.YNode().Kind != yaml.SequenceNode {
+			return nil, errors.Errorf("unable to append to %q, the field is not a list", strings.Join(parts[:i], PathDelimiter))
 		}
-		return len(patternParts) > 1 && (patternParts[1] == "*]" || elemParts[1] == patternParts[1])
+		elem := yaml.NewMapRNode(nil)
+		node.YNode().Content = append(node.YNode().Content, elem.YNode())
+		node = elem
+		parts = parts[i+1:]
 	}
-	return false
+	return node, nil
 }
 
 // isAbsPath checks if input path is absolute and not a path expression
-// only supported path format is e.g. foo.bar.baz
+// e.g. foo.bar.baz, foo.bar[0].baz, foo.bar[name=baz] or foo.bar[+]
 func isAbsPath(path string) bool {
-	pathElem := strings.Split(path, PathDelimiter)
+	pathElem := splitPath(path)
 	if len(pathElem) == 0 {
 		return false
 	}
@@ -84,3 +84,30 @@ func TestPathMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestLookupParts(t *testing.T) {
+	var tests = []struct {
+		path     string
+		expected []string
+	}{
+		{
+			path:     "a.b.c",
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			path:     "a[name=nginx].b[0].c[+]",
+			expected: []string{"a", "[name=nginx]", "b", "0", "c", "+"},
+		},
+		{
+			path:     "metadata.labels[app.kubernetes.io/name=foo].bar",
+			expected: []string{"metadata", "labels", "[app.kubernetes.io/name=foo]", "bar"},
+		},
+		{
+			path:     "args[=--debug]",
+			expected: []string{"args", "[=--debug]"},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, lookupParts(test.path), test.path)
+	}
+}
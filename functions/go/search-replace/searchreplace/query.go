@@ -0,0 +1,250 @@
+package searchreplace
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"google.golang.org/protobuf/types/known/structpb"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	QueryLanguageCEL      = "cel"
+	QueryLanguageJSONPath = "jsonpath"
+)
+
+// QueryResult holds the result of the query on a single resource
+type QueryResult struct {
+	// ResourceRef is the reference to the matching resource
+	ResourceRef yaml.ResourceIdentifier
+
+	// FilePath is the file path of the matching resource
+	FilePath string
+
+	// Values are the values returned by the query, empty if the
+	// query is a CEL predicate
+	Values []string
+}
+
+// KindCount holds the number of resources of a kind matching the query
+type KindCount struct {
+	// Kind is the kind of the resources
+	Kind string
+
+	// Count is the number of matching resources of the kind
+	Count int
+}
+
+// compileQuery parses the input query once so that it can be evaluated for each resource
+func (sr *SearchReplace) compileQuery() error {
+	switch sr.QueryLanguage {
+	case "", QueryLanguageCEL:
+		env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		ast, iss := env.Compile(sr.Query)
+		if iss.Err() != nil {
+			return errors.Errorf("failed to compile query %q: %s", sr.Query, iss.Err().Error())
+		}
+		prg, err := env.Program(ast)
+		if err != nil {
+			return errors.Errorf("failed to compile query %q: %s", sr.Query, err.Error())
+		}
+		sr.celProgram = prg
+		sr.celSelections = celSelections(ast)
+	case QueryLanguageJSONPath:
+		query := sr.Query
+		if !strings.Contains(query, "{") {
+			query = "{" + query + "}"
+		}
+		jp := jsonpath.New(Query).AllowMissingKeys(true)
+		if err := jp.Parse(query); err != nil {
+			return errors.Errorf("failed to parse query %q: %s", sr.Query, err.Error())
+		}
+		sr.jsonPath = jp
+	default:
+		return errors.Errorf("invalid %s %q, must be one of [%s, %s]",
+			QueryLanguage, sr.QueryLanguage, QueryLanguageCEL, QueryLanguageJSONPath)
+	}
+	return nil
+}
+
+// celSelections returns the ids of the field selections and the index operations
+// of the CEL expression, the errors of these expressions are missing fields
+func celSelections(ast *cel.Ast) map[int64]bool {
+	selections := map[int64]bool{}
+	root := celast.NavigateAST(ast.NativeRep())
+	matchers := []celast.ExprMatcher{
+		celast.KindMatcher(celast.SelectKind),
+		celast.FunctionMatcher(operators.Index),
+		celast.FunctionMatcher(operators.OptIndex),
+	}
+	for _, matcher := range matchers {
+		for _, e := range celast.MatchDescendants(root, matcher) {
+			selections[e.ID()] = true
+		}
+	}
+	return selections
+}
+
+/*
+performQuery evaluates the query on the resource and records the resource if it
+matches, the resource is not changed
+
+e.g. the CEL query object.spec.replicas > 3 matches the Deployments with more
+than 3 replicas, the CEL query object.spec.template.spec.containers.map(c, c.image)
+and the JSONPath query .spec.template.spec.containers[*].image match the resources
+with containers and return the images
+*/
+func (sr *SearchReplace) performQuery(object *yaml.RNode) error {
+	m, err := object.Map()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	var match bool
+	var values []string
+	if sr.jsonPath != nil {
+		match, values, err = sr.evalJSONPath(m)
+	} else {
+		match, values, err = sr.evalCEL(m)
+	}
+	if err != nil {
+		return errors.Errorf("failed to evaluate query on %s %q: %s", object.GetKind(), object.GetName(), err.Error())
+	}
+	if !match {
+		return nil
+	}
+	sr.QueryResults = append(sr.QueryResults, QueryResult{
+		ResourceRef: yaml.ResourceIdentifier{
+			TypeMeta: yaml.TypeMeta{APIVersion: object.GetApiVersion(), Kind: object.GetKind()},
+			NameMeta: yaml.NameMeta{Name: object.GetName(), Namespace: object.GetNamespace()},
+		},
+		FilePath: sr.filePath,
+		Values:   values,
+	})
+	sr.Count++
+	return nil
+}
+
+// evalCEL evaluates the CEL query, a boolean result matches the resource if it is true,
+// a list result matches if it is not empty and any other result matches if it is not null,
+// selecting a missing field doesn't match the resource
+func (sr *SearchReplace) evalCEL(m map[string]interface{}) (bool, []string, error) {
+	out, _, err := sr.celProgram.Eval(map[string]interface{}{"object": m})
+	if err != nil {
+		var evalErr *types.Err
+		if goerrors.As(err, &evalErr) && sr.celSelections[evalErr.NodeID()] {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	val, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return false, nil, err
+	}
+	switch v := val.(*structpb.Value).AsInterface().(type) {
+	case nil:
+		return false, nil, nil
+	case bool:
+		return v, nil, nil
+	case []interface{}:
+		values, err := formatValues(v)
+		return len(values) > 0, values, err
+	default:
+		values, err := formatValues([]interface{}{v})
+		return true, values, err
+	}
+}
+
+// evalJSONPath evaluates the JSONPath query, the resource matches if the query
+// returns any value
+func (sr *SearchReplace) evalJSONPath(m map[string]interface{}) (bool, []string, error) {
+	results, err := sr.jsonPath.FindResults(m)
+	if err != nil {
+		return false, nil, err
+	}
+	var found []interface{}
+	for _, result := range results {
+		for _, r := range result {
+			if r.IsValid() && r.CanInterface() && r.Interface() != nil {
+				found = append(found, r.Interface())
+			}
+		}
+	}
+	values, err := formatValues(found)
+	return len(values) > 0, values, err
+}
+
+// formatValues formats the values returned by the query, strings are
+// returned as is and any other value is formatted as JSON
+func formatValues(values []interface{}) ([]string, error) {
+	var out []string
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		out = append(out, string(b))
+	}
+	return out, nil
+}
+
+// KindCounts returns the number of resources matching the query per kind sorted by kind
+func (sr *SearchReplace) KindCounts() []KindCount {
+	counts := map[string]int{}
+	for _, res := range sr.QueryResults {
+		counts[res.ResourceRef.Kind]++
+	}
+	var out []KindCount
+	for kind, count := range counts {
+		out = append(out, KindCount{Kind: kind, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Kind < out[j].Kind
+	})
+	return out
+}
+
+// queryResultsString returns the serialized string results of the query
+func (sr *SearchReplace) queryResultsString() string {
+	var out string
+	for _, res := range sr.QueryResults {
+		out += fmt.Sprintf("%s\nresource: %s\n", res.FilePath, resourceString(res.ResourceRef))
+		if len(res.Values) > 0 {
+			out += fmt.Sprintf("values: [%s]\n", strings.Join(res.Values, ", "))
+		}
+		out += "\n"
+	}
+	for _, kc := range sr.KindCounts() {
+		out += fmt.Sprintf("%s: %d\n", kc.Kind, kc.Count)
+	}
+	out += fmt.Sprintf("Matched %d resource(s)\n", sr.Count)
+	return out
+}
+
+// resourceString returns the resource reference e.g. apps/v1/Deployment/default/frontend
+func resourceString(id yaml.ResourceIdentifier) string {
+	parts := []string{id.APIVersion, id.Kind}
+	if id.Namespace != "" {
+		parts = append(parts, id.Namespace)
+	}
+	return strings.Join(append(parts, id.Name), "/")
+}
@@ -0,0 +1,425 @@
+package searchreplace
+
+var queryCases = []test{
+	{
+		name: "query with CEL predicate",
+		config: `
+data:
+  query: object.spec.replicas > 3
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: prod
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: prod
+spec:
+  replicas: 2
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: prod
+spec:
+  replicas: 4
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  namespace: prod
+`,
+		out: `${filePath}
+resource: apps/v1/Deployment/prod/frontend
+
+${filePath}
+resource: apps/v1/StatefulSet/prod/db
+
+Deployment: 1
+StatefulSet: 1
+Matched 2 resource(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+  namespace: prod
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+  namespace: prod
+spec:
+  replicas: 2
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+  namespace: prod
+spec:
+  replicas: 4
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: frontend
+  namespace: prod
+`,
+	},
+	{
+		name: "query with CEL values and resource matcher",
+		config: `
+data:
+  by-kind: Deployment
+  query: object.spec.template.spec.containers.map(c, c.image)
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+      - name: sidecar
+        image: envoy:1.0
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  template:
+    spec:
+      containers:
+      - name: busybox
+        image: busybox
+`,
+		out: `${filePath}
+resource: apps/v1/Deployment/frontend
+values: [nginx:1.21, envoy:1.0]
+
+Deployment: 1
+Matched 1 resource(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+      - name: sidecar
+        image: envoy:1.0
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  template:
+    spec:
+      containers:
+      - name: busybox
+        image: busybox
+`,
+	},
+	{
+		name: "query with JSONPath",
+		config: `
+data:
+  query: '.spec.template.spec.containers[?(@.name=="nginx")].resources'
+  query-language: jsonpath
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        resources:
+          limits:
+            cpu: 500m
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+`,
+		out: `${filePath}
+resource: apps/v1/Deployment/frontend
+values: [{"limits":{"cpu":"500m"}}]
+
+Deployment: 1
+Matched 1 resource(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        resources:
+          limits:
+            cpu: 500m
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+`,
+	},
+	{
+		name: "query with CEL missing map key",
+		config: `
+data:
+  query: object.metadata.labels["app"] == "web"
+`,
+		input: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+`,
+		out: `${filePath}
+resource: v1/Service/web
+
+Service: 1
+Matched 1 resource(s)
+`,
+		expectedResources: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    app: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+`,
+	},
+	{
+		name: "query with CEL evaluation error",
+		config: `
+data:
+  query: object.metadata.name < 1
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `failed to evaluate query on Pod "nginx": no such overload`,
+	},
+	{
+		name: "query with JSONPath recursive descent, index and numeric filter",
+		config: `
+data:
+  query: '{$..containers[?(@.ports[0].containerPort >= 8080)].image}'
+  query-language: jsonpath
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+        ports:
+        - containerPort: 80
+      - name: proxy
+        image: envoy:1.30
+        ports:
+        - containerPort: 8443
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: backend
+spec:
+  containers:
+  - name: app
+    image: app:2.0
+    ports:
+    - containerPort: 8080
+`,
+		out: `${filePath}
+resource: apps/v1/Deployment/frontend
+values: [envoy:1.30]
+
+${filePath}
+resource: v1/Pod/backend
+values: [app:2.0]
+
+Deployment: 1
+Pod: 1
+Matched 2 resource(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.21
+        ports:
+        - containerPort: 80
+      - name: proxy
+        image: envoy:1.30
+        ports:
+        - containerPort: 8443
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: backend
+spec:
+  containers:
+  - name: app
+    image: app:2.0
+    ports:
+    - containerPort: 8080
+`,
+	},
+	{
+		name: "query with JSONPath wildcard and slice",
+		config: `
+data:
+  query: .spec.template.spec.containers[-2:].*
+  query-language: jsonpath
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+      - image: envoy:1.30
+        name: proxy
+`,
+		out: `${filePath}
+resource: apps/v1/Deployment/frontend
+values: [nginx, envoy:1.30, proxy]
+
+Deployment: 1
+Matched 1 resource(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+      - image: envoy:1.30
+        name: proxy
+`,
+	},
+	{
+		name: "invalid JSONPath query",
+		config: `
+data:
+  query: .spec.containers[?(@.name=="nginx")
+  query-language: jsonpath
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `failed to parse query ".spec.containers[?(@.name==\"nginx\")": unclosed array expect ]`,
+	},
+	{
+		name: "query is read-only",
+		config: `
+data:
+  query: object.spec.replicas > 3
+  put-value: '3'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `"put-value" can't be provided with "query", the query is read-only`,
+	},
+	{
+		name: "invalid CEL query",
+		config: `
+data:
+  query: object.spec.replicas >
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `failed to compile query "object.spec.replicas >"`,
+	},
+	{
+		name: "invalid query language",
+		config: `
+data:
+  query: object.spec.replicas > 3
+  query-language: rego
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `invalid query-language "rego", must be one of [cel, jsonpath]`,
+	},
+}
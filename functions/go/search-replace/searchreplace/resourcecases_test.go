@@ -0,0 +1,164 @@
+package searchreplace
+
+var resourceMatcherCases = []test{
+	{
+		name: "replace by path in the resource matched by kind and name",
+		config: `
+data:
+  by-kind: Deployment
+  by-name: frontend
+  by-path: spec.replicas
+  put-value: '5'
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: frontend
+spec:
+  replicas: 3
+`,
+		out: `${filePath}
+fieldPath: spec.replicas
+value: 5
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 5
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: backend
+spec:
+  replicas: 3
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: frontend
+spec:
+  replicas: 3
+`,
+	},
+	{
+		name: "search by api version, namespace and label selector",
+		config: `
+data:
+  by-api-version: v1
+  by-namespace: prod
+  by-label-selector: app=nginx,tier!=cache
+  by-value: nginx
+`,
+		input: `apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+    tier: frontend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: dev
+  labels:
+    app: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+    tier: cache
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+`,
+		out: `${filePath}
+fieldPath: metadata.name
+value: nginx
+
+${filePath}
+fieldPath: metadata.labels.app
+value: nginx
+
+Matched 2 field(s)
+`,
+		expectedResources: `apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+    tier: frontend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: dev
+  labels:
+    app: nginx
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+    tier: cache
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: prod
+  labels:
+    app: nginx
+`,
+	},
+	{
+		name: "invalid label selector",
+		config: `
+data:
+  by-label-selector: 'app in (nginx'
+  by-value: nginx
+`,
+		input: `apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+`,
+		errMsg: `invalid by-label-selector "app in (nginx"`,
+	},
+}
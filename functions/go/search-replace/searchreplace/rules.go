@@ -0,0 +1,142 @@
+package searchreplace
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	RulesKind        = "SearchReplaceRules"
+	RulesAPIVersion  = "fn.kpt.dev/v1alpha1"
+	RuleName         = "name"
+	RuleOnNoMatch    = "on-no-match"
+	OnNoMatchIgnore  = "ignore"
+	OnNoMatchWarning = "warning"
+	OnNoMatchError   = "error"
+)
+
+// Rule is a single search and replace operation of SearchReplaceRules
+type Rule struct {
+	// Name is the name of the rule used to group the results
+	Name string
+
+	// OnNoMatch is the action if the rule doesn't match any field,
+	// one of ignore, warning or error
+	OnNoMatch string
+
+	SearchReplace
+}
+
+// SearchReplaceRules holds the ordered list of rules which are applied
+// to the resources in a single traversal
+type SearchReplaceRules struct {
+	// Rules are the search and replace rules applied in order
+	Rules []*Rule
+}
+
+// IsRules returns true if the function config is a SearchReplaceRules resource
+func IsRules(rn *yaml.RNode) bool {
+	return rn != nil && rn.GetKind() == RulesKind
+}
+
+// Filter applies all the rules to each input node, the rules are applied to a node
+// in order so that a rule sees the changes made by the rules before it
+func (srr *SearchReplaceRules) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
+	for _, rule := range srr.Rules {
+		if err := rule.prepare(); err != nil {
+			return nodes, errors.Errorf("rule %q: %s", rule.Name, err.Error())
+		}
+	}
+	for _, object := range nodes {
+		for _, rule := range srr.Rules {
+			if _, err := rule.Perform(object); err != nil {
+				return nodes, errors.Errorf("rule %q: %s", rule.Name, err.Error())
+			}
+		}
+	}
+	return nodes, nil
+}
+
+/*
+DecodeRules decodes the SearchReplaceRules resource, each rule holds the same
+matcher keys as the ConfigMap e.g.
+
+	apiVersion: fn.kpt.dev/v1alpha1
+	kind: SearchReplaceRules
+	metadata:
+	  name: my-rules
+	rules:
+	- name: scale-frontend
+	  on-no-match: error
+	  by-kind: Deployment
+	  by-name: frontend
+	  by-path: spec.replicas
+	  put-value: 5
+*/
+func DecodeRules(rn *yaml.RNode, srr *SearchReplaceRules) error {
+	if rn.GetApiVersion() != RulesAPIVersion {
+		return errors.Errorf("unsupported apiVersion %q for %s, expected %q",
+			rn.GetApiVersion(), RulesKind, RulesAPIVersion)
+	}
+	rules, err := rn.Pipe(yaml.Lookup("rules"))
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if rules == nil || len(rules.Content()) == 0 {
+		return errors.Errorf("%s must have at least one rule", RulesKind)
+	}
+	if rules.YNode().Kind != yaml.SequenceNode {
+		return errors.Errorf("rules of %s must be a list", RulesKind)
+	}
+	names := map[string]bool{}
+	for i, node := range rules.Content() {
+		rule, err := decodeRule(yaml.NewRNode(node), i)
+		if err != nil {
+			return err
+		}
+		if names[rule.Name] {
+			return errors.Errorf("duplicate rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+		srr.Rules = append(srr.Rules, rule)
+	}
+	return nil
+}
+
+// decodeRule decodes the rule at the input index, the rule name
+// defaults to rule-<index> starting from 1
+func decodeRule(rn *yaml.RNode, index int) (*Rule, error) {
+	if rn.YNode().Kind != yaml.MappingNode {
+		return nil, errors.Errorf("rule %d of %s must be a map", index+1, RulesKind)
+	}
+	rule := &Rule{Name: fmt.Sprintf("rule-%d", index+1), OnNoMatch: OnNoMatchIgnore}
+	dm := map[string]string{}
+	err := rn.VisitFields(func(node *yaml.MapNode) error {
+		if node.Value.YNode().Kind != yaml.ScalarNode {
+			return errors.Errorf("value of %q must be a string", node.Key.YNode().Value)
+		}
+		dm[node.Key.YNode().Value] = node.Value.YNode().Value
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Errorf("rule %q: %s", rule.Name, err.Error())
+	}
+	if v, ok := dm[RuleName]; ok {
+		rule.Name = v
+		delete(dm, RuleName)
+	}
+	if v, ok := dm[RuleOnNoMatch]; ok {
+		if v != OnNoMatchIgnore && v != OnNoMatchWarning && v != OnNoMatchError {
+			return nil, errors.Errorf("invalid %s %q for rule %q, must be one of [%s, %s, %s]",
+				RuleOnNoMatch, v, rule.Name, OnNoMatchIgnore, OnNoMatchWarning, OnNoMatchError)
+		}
+		rule.OnNoMatch = v
+		delete(dm, RuleOnNoMatch)
+	}
+	if err := decodeMatchers(dm, &rule.SearchReplace); err != nil {
+		return nil, errors.Errorf("rule %q: %s", rule.Name, err.Error())
+	}
+	return rule, nil
+}
@@ -0,0 +1,193 @@
+package searchreplace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+func TestSearchReplaceRules(t *testing.T) {
+	var tests = []struct {
+		name              string
+		config            string
+		input             string
+		expectedResources string
+		expectedCounts    map[string]int
+		errMsg            string
+	}{
+		{
+			name: "rules are applied in order",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+rules:
+- name: scale-frontend
+  by-kind: Deployment
+  by-name: frontend
+  by-path: spec.replicas
+  put-value: 5
+- name: rename-image
+  by-value-regex: nginx:(.*)
+  put-value: ghcr.io/nginx:${1}
+- by-value: ghcr.io/nginx:1.16
+  put-comment: 'kpt-set: ghcr.io/nginx:${tag}'
+- name: unused
+  on-no-match: warning
+  by-value: foo
+`,
+			input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.16
+`,
+			expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: frontend
+spec:
+  replicas: 5
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: ghcr.io/nginx:1.16 # kpt-set: ghcr.io/nginx:${tag}
+`,
+			expectedCounts: map[string]int{"scale-frontend": 1, "rename-image": 1, "rule-3": 1, "unused": 0},
+		},
+		{
+			name: "invalid matcher in rule",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+rules:
+- name: bad
+  by-values: foo
+`,
+			errMsg: `rule "bad": invalid matcher "by-values"`,
+		},
+		{
+			name: "invalid on-no-match",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+rules:
+- by-value: foo
+  on-no-match: fail
+`,
+			errMsg: `invalid on-no-match "fail" for rule "rule-1", must be one of [ignore, warning, error]`,
+		},
+		{
+			name: "duplicate rule names",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+rules:
+- name: foo
+  by-value: foo
+- name: foo
+  by-value: bar
+`,
+			errMsg: `duplicate rule name "foo"`,
+		},
+		{
+			name: "no rules",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+`,
+			errMsg: `SearchReplaceRules must have at least one rule`,
+		},
+		{
+			name: "invalid rule matchers",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SearchReplaceRules
+metadata:
+  name: my-rules
+rules:
+- name: both-values
+  by-value: foo
+  by-value-regex: foo.*
+`,
+			input: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+`,
+			errMsg: `rule "both-values": only one of ["by-value", "by-value-regex"] can be provided`,
+		},
+	}
+	for i := range tests {
+		test := tests[i]
+		t.Run(test.name, func(t *testing.T) {
+			rn, err := kyaml.Parse(test.config)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			srr := &SearchReplaceRules{}
+			err = DecodeRules(rn, srr)
+			if err == nil {
+				err = applyRules(t, srr, test.input, test.expectedResources)
+			}
+			if test.errMsg != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), test.errMsg)
+				return
+			}
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			counts := map[string]int{}
+			for _, rule := range srr.Rules {
+				counts[rule.Name] = rule.Count
+			}
+			assert.Equal(t, test.expectedCounts, counts)
+		})
+	}
+}
+
+// applyRules runs the rules on the input written to a package directory
+// and checks the resources written back to it
+func applyRules(t *testing.T, srr *SearchReplaceRules, input, expected string) error {
+	baseDir := t.TempDir()
+	file := filepath.Join(baseDir, "resources.yaml")
+	if !assert.NoError(t, os.WriteFile(file, []byte(input), 0600)) {
+		t.FailNow()
+	}
+	inout := &kio.LocalPackageReadWriter{
+		PackagePath:     baseDir,
+		NoDeleteFiles:   true,
+		PackageFileName: "Kptfile",
+	}
+	err := kio.Pipeline{
+		Inputs:  []kio.Reader{inout},
+		Filters: []kio.Filter{srr},
+		Outputs: []kio.Writer{inout},
+	}.Execute()
+	if err != nil {
+		return err
+	}
+	actual, err := os.ReadFile(file)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, expected, string(actual))
+	return nil
+}
@@ -3,9 +3,12 @@ package searchreplace
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/google/cel-go/cel"
+	"k8s.io/client-go/util/jsonpath"
 	"sigs.k8s.io/kustomize/kyaml/errors"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
 	"sigs.k8s.io/kustomize/kyaml/sets"
@@ -13,18 +16,29 @@ import (
 )
 
 const (
-	ByValue       = "by-value"
-	ByValueRegex  = "by-value-regex"
-	ByPath        = "by-path"
-	ByFilePath    = "by-file-path"
-	PutValue      = "put-value"
-	PutComment    = "put-comment"
-	PathDelimiter = "."
+	ByValue         = "by-value"
+	ByValueRegex    = "by-value-regex"
+	ByPath          = "by-path"
+	ByFilePath      = "by-file-path"
+	ByKind          = "by-kind"
+	ByAPIVersion    = "by-api-version"
+	ByName          = "by-name"
+	ByNamespace     = "by-namespace"
+	ByLabelSelector = "by-label-selector"
+	Query           = "query"
+	QueryLanguage   = "query-language"
+	PutValue        = "put-value"
+	PutComment      = "put-comment"
+	PutYAML         = "put-yaml"
+	AppendYAML      = "append-yaml"
+	Delete          = "delete"
+	PathDelimiter   = "."
 )
 
 // matchers returns the list of supported matchers
 func matchers() []string {
-	return []string{ByValue, ByFilePath, ByValueRegex, ByPath, PutValue, PutComment}
+	return []string{ByValue, ByFilePath, ByValueRegex, ByPath, ByKind, ByAPIVersion, ByName, ByNamespace,
+		ByLabelSelector, Query, QueryLanguage, PutValue, PutComment, PutYAML, AppendYAML, Delete}
 }
 
 // SearchReplace struct holds the input parameters and results for
@@ -42,6 +56,29 @@ type SearchReplace struct {
 	// ByFilePath is the filepath of the resource to be matched
 	ByFilePath string
 
+	// ByKind is the kind of the resource to be matched
+	ByKind string
+
+	// ByAPIVersion is the apiVersion of the resource to be matched
+	ByAPIVersion string
+
+	// ByName is the name of the resource to be matched
+	ByName string
+
+	// ByNamespace is the namespace of the resource to be matched
+	ByNamespace string
+
+	// ByLabelSelector is the label selector of the resources to be matched
+	// e.g. app=nginx,tier!=frontend
+	ByLabelSelector string
+
+	// Query is the CEL or JSONPath expression evaluated on each resource for
+	// read-only search e.g. object.spec.replicas > 3
+	Query string
+
+	// QueryLanguage is the language of the query, one of cel or jsonpath
+	QueryLanguage string
+
 	// Count is the number of matches
 	Count int
 
@@ -52,14 +89,40 @@ type SearchReplace struct {
 	// PutComment is the comment to be added at to field
 	PutComment string
 
+	// PutYAML is the yaml snippet to be put at the field
+	// filtered by path and/or value
+	PutYAML string
+
+	// AppendYAML is the yaml snippet to be appended to the sequence
+	// field filtered by path
+	AppendYAML string
+
+	// Delete deletes the field or the element filtered by path and/or value
+	Delete bool
+
 	// Results stores the results of executing the command
 	Results []SearchResult
 
+	// QueryResults stores the resources matching the query
+	QueryResults []QueryResult
+
 	// regex compiled regular expression for input by-value-regex
 	regex *regexp.Regexp
 
 	// filePath file path of resource
 	filePath string
+
+	// celProgram is the compiled CEL query
+	celProgram cel.Program
+
+	// celSelections are the ids of the field selections and index operations of the CEL query
+	celSelections map[int64]bool
+
+	// jsonPath is the parsed JSONPath query
+	jsonPath *jsonpath.JSONPath
+
+	// object is the resource being traversed, used to match list elements by key
+	object *yaml.RNode
 }
 
 // SearchResult holds result of search and replace operation
@@ -76,19 +139,10 @@ type SearchResult struct {
 
 // Filter performs the search and replace operation on all input nodes
 func (sr *SearchReplace) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
-	if err := sr.validateMatchers(); err != nil {
+	if err := sr.prepare(); err != nil {
 		return nodes, err
 	}
 
-	// compile regex once so that it can be used everywhere
-	if sr.ByValueRegex != "" {
-		re, err := regexp.Compile(sr.ByValueRegex)
-		if err != nil {
-			return nodes, errors.Wrap(err)
-		}
-		sr.regex = re
-	}
-
 	// perform search/replace on all nodes
 	for _, object := range nodes {
 		_, err := sr.Perform(object)
@@ -99,6 +153,25 @@ func (sr *SearchReplace) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 	return nodes, nil
 }
 
+// prepare validates the input matchers and compiles the regex once
+// so that it can be used everywhere
+func (sr *SearchReplace) prepare() error {
+	if err := sr.validateMatchers(); err != nil {
+		return err
+	}
+	if sr.ByValueRegex != "" {
+		re, err := regexp.Compile(sr.ByValueRegex)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		sr.regex = re
+	}
+	if sr.Query != "" {
+		return sr.compileQuery()
+	}
+	return nil
+}
+
 // Perform parses input node and performs search and replace operation on the node
 func (sr *SearchReplace) Perform(object *yaml.RNode) (*yaml.RNode, error) {
 	// get the filepath from the annotations to pass it to child methods
@@ -117,17 +190,31 @@ func (sr *SearchReplace) Perform(object *yaml.RNode) (*yaml.RNode, error) {
 		}
 	}
 
-	sr.filePath = filePath
-	if err != nil {
+	match, err := sr.resourceMatch(object)
+	if err != nil || !match {
 		return object, err
 	}
 
+	sr.filePath = filePath
+	sr.object = object
+
+	// the query is evaluated on the whole resource without traversing it
+	if sr.Query != "" {
+		return object, sr.performQuery(object)
+	}
+
 	// check if value should be put by path and process it directly without needing
 	// to traverse all elements of the node
 	if sr.shouldPutValueByPath() {
 		return object, sr.putValueByPath(object)
 	}
 
+	// operations on non-scalar nodes are performed on the matched nodes
+	// after the traversal as they change the structure of the node
+	if sr.hasStructuralOperation() {
+		return object, sr.performStructural(object)
+	}
+
 	// traverse the node to perform search/put operation
 	err = accept(sr, object)
 	return object, err
@@ -295,42 +382,133 @@ func (sr *SearchReplace) searchCriteriaMatch(node *yaml.Node, path string) bool
 		(pathMatch && sr.ByValue == "" && sr.ByValueRegex == "") // match by path only
 }
 
-// putValueByPath puts the value in the user specified sr.ByPath
+// resourceMatch checks if the resource matches the input resource identity matchers
+func (sr *SearchReplace) resourceMatch(object *yaml.RNode) (bool, error) {
+	if sr.ByKind != "" && sr.ByKind != object.GetKind() {
+		return false, nil
+	}
+	if sr.ByAPIVersion != "" && sr.ByAPIVersion != object.GetApiVersion() {
+		return false, nil
+	}
+	if sr.ByName != "" && sr.ByName != object.GetName() {
+		return false, nil
+	}
+	if sr.ByNamespace != "" && sr.ByNamespace != object.GetNamespace() {
+		return false, nil
+	}
+	if sr.ByLabelSelector != "" {
+		return object.MatchesLabelSelector(sr.ByLabelSelector)
+	}
+	return true, nil
+}
+
+// putValueByPath puts the value in the user specified sr.ByPath, the list
+// elements selected by key are created and [+] appends a new element
 func (sr *SearchReplace) putValueByPath(object *yaml.RNode) error {
-	path := strings.Split(sr.ByPath, PathDelimiter)
-	// lookup(or create) node for n-1 path elements
-	node, err := object.Pipe(yaml.LookupCreate(yaml.MappingNode, path[:len(path)-1]...))
-	if err != nil {
-		return errors.Wrap(err)
+	parts := lookupParts(sr.ByPath)
+	// list elements selected by index are not created, skip the resource
+	// if any index is out of range
+	if ok, err := indexesExist(object, parts); err != nil || !ok {
+		return err
+	}
+	if sr.AppendYAML != "" {
+		return sr.appendValueByPath(object, parts)
 	}
-	// set the last path element key with the input value
 	sn := yaml.NewScalarRNode(sr.PutValue)
 	// When encoding, if this tag is unset the value type will be
 	// implied from the node properties
 	sn.YNode().Tag = yaml.NodeTagEmpty
-	err = node.PipeE(yaml.SetField(path[len(path)-1], sn))
-	if err != nil {
-		return errors.Wrap(err)
+	value := sr.PutValue
+	if sr.PutYAML != "" {
+		var err error
+		sn, err = parseSnippet(sr.PutYAML)
+		if err != nil {
+			return err
+		}
+		value, err = flowString(sn.YNode())
+		if err != nil {
+			return err
+		}
+	}
+	// lookup(or create) node for n-1 path parts
+	last := parts[len(parts)-1]
+	kind := yaml.MappingNode
+	if last == appendMarker || isIndex(last) || yaml.IsListIndex(last) {
+		kind = yaml.SequenceNode
+	}
+	node, err := lookupCreatePath(object, parts[:len(parts)-1], kind)
+	if err != nil || node == nil {
+		return err
+	}
+	if kind == yaml.SequenceNode && node.YNode().Kind != yaml.SequenceNode {
+		return errors.Errorf("unable to put value to %q, the field is not a list", sr.ByPath)
+	}
+	// set the last path part with the input value
+	switch {
+	case last == appendMarker:
+		node.YNode().Content = append(node.YNode().Content, sn.YNode())
+	case isIndex(last):
+		idx, _ := strconv.Atoi(last)
+		node.Content()[idx] = sn.YNode()
+	case yaml.IsListIndex(last):
+		elem, err := node.Pipe(yaml.Lookup(last))
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		if elem == nil {
+			node.YNode().Content = append(node.YNode().Content, sn.YNode())
+		} else {
+			*elem.YNode() = *sn.YNode()
+		}
+	default:
+		if err := node.PipeE(yaml.SetField(last, sn)); err != nil {
+			return errors.Wrap(err)
+		}
 	}
 	res := SearchResult{
 		FilePath:  sr.filePath,
 		FieldPath: sr.ByPath,
-		Value:     sr.PutValue,
+		Value:     value,
 	}
 	sr.Results = append(sr.Results, res)
 	sr.Count++
 	return nil
 }
 
+// appendValueByPath appends the yaml snippet to the sequence in the user specified
+// sr.ByPath, the sequence is created if it doesn't exist
+func (sr *SearchReplace) appendValueByPath(object *yaml.RNode, parts []string) error {
+	node, err := lookupCreatePath(object, parts, yaml.SequenceNode)
+	if err != nil || node == nil {
+		return err
+	}
+	if node.YNode().Kind != yaml.SequenceNode {
+		return errors.Errorf("unable to append to %q, the field is not a list", sr.ByPath)
+	}
+	if err := appendSnippet(node, sr.AppendYAML); err != nil {
+		return err
+	}
+	value, err := flowString(node.YNode())
+	if err != nil {
+		return err
+	}
+	sr.Results = append(sr.Results, SearchResult{
+		FilePath:  sr.filePath,
+		FieldPath: sr.ByPath,
+		Value:     value,
+	})
+	sr.Count++
+	return nil
+}
+
 // shouldPutValueByPath returns true if only absolute path and literal are provided,
 // so that the value can be directly put without needing to traverse the entire node,
 // handles the case of adding non-existent field-value to node
 func (sr *SearchReplace) shouldPutValueByPath() bool {
 	return isAbsPath(sr.ByPath) &&
-		!strings.Contains(sr.ByPath, "[") && // TODO: pmarupaka Support appending value for arrays
 		sr.ByValue == "" &&
 		sr.ByValueRegex == "" &&
-		sr.PutValue != ""
+		(sr.PutValue != "" || sr.PutYAML != "" || sr.AppendYAML != "")
 }
 
 // resolvePattern takes the field value of a node, valueRegex provided by
@@ -365,8 +543,13 @@ func resolvePattern(fieldValue, valueRegex, patternRegex string) (string, error)
 
 // resultsString return the serialized string results
 func (sr *SearchReplace) resultsString() string {
+	if sr.Query != "" {
+		return sr.queryResultsString()
+	}
 	var action string
-	if sr.PutComment != "" || sr.PutValue != "" {
+	if sr.Delete {
+		action = "Deleted"
+	} else if sr.PutComment != "" || sr.PutValue != "" || sr.PutYAML != "" || sr.AppendYAML != "" {
 		action = "Mutated"
 	} else {
 		action = "Matched"
@@ -382,7 +565,11 @@ func (sr *SearchReplace) resultsString() string {
 // Decode decodes the input yaml RNode into SearchReplace struct
 // returns error if input yaml RNode contains invalid matcher name inputs
 func Decode(rn *yaml.RNode, fcd *SearchReplace) error {
-	dm := rn.GetDataMap()
+	return decodeMatchers(rn.GetDataMap(), fcd)
+}
+
+// decodeMatchers decodes the matcher key-value pairs into SearchReplace struct
+func decodeMatchers(dm map[string]string, fcd *SearchReplace) error {
 	if err := validateMatcherNames(dm); err != nil {
 		return err
 	}
@@ -392,6 +579,22 @@ func Decode(rn *yaml.RNode, fcd *SearchReplace) error {
 	fcd.PutValue = dm[PutValue]
 	fcd.PutComment = dm[PutComment]
 	fcd.ByFilePath = dm[ByFilePath]
+	fcd.ByKind = dm[ByKind]
+	fcd.ByAPIVersion = dm[ByAPIVersion]
+	fcd.ByName = dm[ByName]
+	fcd.ByNamespace = dm[ByNamespace]
+	fcd.ByLabelSelector = dm[ByLabelSelector]
+	fcd.Query = dm[Query]
+	fcd.QueryLanguage = dm[QueryLanguage]
+	fcd.PutYAML = dm[PutYAML]
+	fcd.AppendYAML = dm[AppendYAML]
+	if v, ok := dm[Delete]; ok {
+		del, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Errorf("invalid value %q for %q, must be a boolean", v, Delete)
+		}
+		fcd.Delete = del
+	}
 	return nil
 }
 
@@ -412,5 +615,59 @@ func (sr *SearchReplace) validateMatchers() error {
 	if sr.ByValue != "" && sr.ByValueRegex != "" {
 		return errors.Errorf(`only one of [%q, %q] can be provided`, ByValue, ByValueRegex)
 	}
+	if sr.ByLabelSelector != "" {
+		if _, err := yaml.NewMapRNode(nil).MatchesLabelSelector(sr.ByLabelSelector); err != nil {
+			return errors.Errorf("invalid %s %q: %s", ByLabelSelector, sr.ByLabelSelector, err.Error())
+		}
+	}
+	if sr.Query != "" {
+		provided := map[string]bool{
+			ByPath:       sr.ByPath != "",
+			ByValue:      sr.ByValue != "",
+			ByValueRegex: sr.ByValueRegex != "",
+			PutValue:     sr.PutValue != "",
+			PutComment:   sr.PutComment != "",
+			PutYAML:      sr.PutYAML != "",
+			AppendYAML:   sr.AppendYAML != "",
+			Delete:       sr.Delete,
+		}
+		for _, matcher := range matchers() {
+			if provided[matcher] {
+				return errors.Errorf(`%q can't be provided with %q, the query is read-only`, matcher, Query)
+			}
+		}
+	} else if sr.QueryLanguage != "" {
+		return errors.Errorf(`%q must be provided with %q`, Query, QueryLanguage)
+	}
+	var puts []string
+	for matcher, provided := range map[string]bool{
+		PutValue:   sr.PutValue != "",
+		PutYAML:    sr.PutYAML != "",
+		AppendYAML: sr.AppendYAML != "",
+		Delete:     sr.Delete,
+	} {
+		if provided {
+			puts = append(puts, matcher)
+		}
+	}
+	if len(puts) > 1 {
+		return errors.Errorf(`only one of [%q, %q, %q, %q] can be provided`, PutValue, PutYAML, AppendYAML, Delete)
+	}
+	if sr.hasStructuralOperation() {
+		if sr.PutComment != "" {
+			return errors.Errorf(`%q can't be provided with %q`, PutComment, puts[0])
+		}
+		if sr.ByPath == "" && sr.ByValue == "" && sr.ByValueRegex == "" {
+			return errors.Errorf(`at least one of [%q, %q, %q] must be provided with %q`, ByPath, ByValue, ByValueRegex, puts[0])
+		}
+		if sr.AppendYAML != "" && sr.ByPath == "" {
+			return errors.Errorf(`%q must be provided with %q`, ByPath, AppendYAML)
+		}
+	}
+	if strings.Contains(sr.ByPath, "["+appendMarker+"]") &&
+		(!sr.shouldPutValueByPath() || sr.AppendYAML != "") {
+		return errors.Errorf(`"[%s]" in %q can only be used in an absolute path with %q or %q`,
+			appendMarker, ByPath, PutValue, PutYAML)
+	}
 	return nil
 }
@@ -21,7 +21,7 @@ type test struct {
 }
 
 func TestSearchCommand(t *testing.T) {
-	for _, tests := range [][]test{searchReplaceCases, putPatternCases} {
+	for _, tests := range [][]test{searchReplaceCases, putPatternCases, structuralCases, resourceMatcherCases, listPathCases, queryCases} {
 		for i := range tests {
 			test := tests[i]
 			t.Run(test.name, func(t *testing.T) {
@@ -107,7 +107,7 @@ func TestDecode(t *testing.T) {
 	if !assert.Error(t, err) {
 		t.FailNow()
 	}
-	expected := `invalid matcher "put-values", must be one of ["by-value" "by-file-path" "by-value-regex" "by-path" "put-value" "put-comment"]`
+	expected := `invalid matcher "put-values", must be one of ["by-value" "by-file-path" "by-value-regex" "by-path" "by-kind" "by-api-version" "by-name" "by-namespace" "by-label-selector" "query" "query-language" "put-value" "put-comment" "put-yaml" "append-yaml" "delete"]`
 	if !assert.Equal(t, expected, err.Error()) {
 		t.FailNow()
 	}
@@ -0,0 +1,208 @@
+package searchreplace
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// structuralMatch is a node matched for a structural operation
+type structuralMatch struct {
+	// node is the matched node
+	node *yaml.RNode
+
+	// parent is the mapping or sequence node holding the matched node
+	parent *yaml.RNode
+
+	// key is the field name of the matched node if parent is a mapping node
+	key string
+
+	// index is the element index of the matched node if parent is a sequence node
+	index int
+
+	// path is the field path of the matched node
+	path string
+}
+
+// hasStructuralOperation returns true if any of the operations on
+// non-scalar nodes is provided
+func (sr *SearchReplace) hasStructuralOperation() bool {
+	return sr.PutYAML != "" || sr.AppendYAML != "" || sr.Delete
+}
+
+/*
+performStructural matches the nodes of any kind and puts a yaml snippet,
+appends to the matched sequence nodes or deletes the matched fields and elements
+
+e.g. for input by-path = spec.template.spec.containers and
+append-yaml = '{name: sidecar, image: envoy}', the node
+
+	spec:
+	  template:
+	    spec:
+	      containers:
+	      - name: nginx
+
+is transformed to
+
+	spec:
+	  template:
+	    spec:
+	      containers:
+	      - name: nginx
+	      - name: sidecar
+	        image: envoy
+*/
+func (sr *SearchReplace) performStructural(object *yaml.RNode) error {
+	var matches []structuralMatch
+	if err := sr.findStructuralMatches(object, nil, "", 0, "", &matches); err != nil {
+		return err
+	}
+	// apply in reverse order so that deleting an element doesn't shift
+	// the index of the elements matched before it
+	results := make([]*SearchResult, len(matches))
+	for i := len(matches) - 1; i >= 0; i-- {
+		res, err := sr.applyStructural(matches[i])
+		if err != nil {
+			return err
+		}
+		results[i] = res
+	}
+	// report the results in the order of traversal
+	for _, res := range results {
+		if res != nil {
+			sr.Results = append(sr.Results, *res)
+			sr.Count++
+		}
+	}
+	return nil
+}
+
+// findStructuralMatches traverses the node and collects the nodes matching the
+// search criteria, the children of matched nodes are not traversed
+func (sr *SearchReplace) findStructuralMatches(object, parent *yaml.RNode, key string, index int,
+	path string, matches *[]structuralMatch) error {
+	if parent != nil && sr.structuralCriteriaMatch(object, path) {
+		*matches = append(*matches, structuralMatch{node: object, parent: parent, key: key, index: index, path: path})
+		return nil
+	}
+	switch object.YNode().Kind {
+	case yaml.DocumentNode:
+		return sr.findStructuralMatches(yaml.NewRNode(object.YNode().Content[0]), nil, "", 0, path, matches)
+	case yaml.MappingNode:
+		return object.VisitFields(func(node *yaml.MapNode) error {
+			return sr.findStructuralMatches(node.Value, object, node.Key.YNode().Value, 0,
+				path+PathDelimiter+node.Key.YNode().Value, matches)
+		})
+	case yaml.SequenceNode:
+		return VisitElements(object, func(node *yaml.RNode, i int) error {
+			return sr.findStructuralMatches(node, object, "", i, path+fmt.Sprintf("[%d]", i), matches)
+		})
+	}
+	return nil
+}
+
+// structuralCriteriaMatch checks if the node matches the search criteria,
+// nodes of any kind are matched by path, only scalar nodes are matched by value
+func (sr *SearchReplace) structuralCriteriaMatch(object *yaml.RNode, path string) bool {
+	if object.YNode().Kind == yaml.ScalarNode {
+		return sr.searchCriteriaMatch(object.YNode(), path)
+	}
+	return sr.ByValue == "" && sr.ByValueRegex == "" && sr.pathMatch(path)
+}
+
+// applyStructural performs the structural operation on the matched node
+// and returns the result, the result is nil if the node is not changed
+func (sr *SearchReplace) applyStructural(m structuralMatch) (*SearchResult, error) {
+	var value *yaml.Node
+	switch {
+	case sr.Delete:
+		value = m.node.YNode()
+		if m.parent.YNode().Kind == yaml.MappingNode {
+			if _, err := m.parent.Pipe(yaml.Clear(m.key)); err != nil {
+				return nil, errors.Wrap(err)
+			}
+		} else {
+			content := m.parent.YNode().Content
+			m.parent.YNode().Content = append(content[:m.index], content[m.index+1:]...)
+		}
+	case sr.PutYAML != "":
+		snippet, err := parseSnippet(sr.PutYAML)
+		if err != nil {
+			return nil, err
+		}
+		*m.node.YNode() = *snippet.YNode()
+		value = m.node.YNode()
+	case sr.AppendYAML != "":
+		if m.node.YNode().Kind != yaml.SequenceNode {
+			// only sequence nodes can be appended to
+			return nil, nil
+		}
+		if err := appendSnippet(m.node, sr.AppendYAML); err != nil {
+			return nil, err
+		}
+		value = m.node.YNode()
+	}
+	val, err := flowString(value)
+	if err != nil {
+		return nil, err
+	}
+	return &SearchResult{
+		FilePath:  sr.filePath,
+		FieldPath: strings.TrimPrefix(m.path, PathDelimiter),
+		Value:     val,
+	}, nil
+}
+
+// parseSnippet parses the input yaml snippet, returns error if it is invalid,
+// maps and lists of the snippet are changed to block style to match the resources
+func parseSnippet(snippet string) (*yaml.RNode, error) {
+	rn, err := yaml.Parse(snippet)
+	if err != nil {
+		return nil, errors.Errorf("failed to parse yaml snippet %q: %s", snippet, err.Error())
+	}
+	blockStyle(rn.YNode())
+	return rn, nil
+}
+
+// blockStyle changes the style of the node and its children to block style
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		return
+	}
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// appendSnippet appends the yaml snippet to the sequence node, all the elements
+// are appended if the snippet is a sequence
+func appendSnippet(seq *yaml.RNode, snippet string) error {
+	rn, err := parseSnippet(snippet)
+	if err != nil {
+		return err
+	}
+	elements := []*yaml.Node{rn.YNode()}
+	if rn.YNode().Kind == yaml.SequenceNode {
+		elements = rn.YNode().Content
+	}
+	seq.YNode().Content = append(seq.YNode().Content, elements...)
+	return nil
+}
+
+// flowString returns the node serialized in flow style e.g. {name: sidecar, image: envoy}
+func flowString(node *yaml.Node) (string, error) {
+	n := yaml.CopyYNode(node)
+	n.LineComment, n.HeadComment, n.FootComment = "", "", ""
+	if n.Kind != yaml.ScalarNode {
+		n.Style = yaml.FlowStyle
+	}
+	val, err := yaml.String(n)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(val), nil
+}
@@ -0,0 +1,297 @@
+package searchreplace
+
+var structuralCases = []test{
+	{
+		name: "put yaml by path",
+		config: `
+data:
+  by-path: spec.template.spec.securityContext
+  put-yaml: |
+    runAsNonRoot: true
+    runAsUser: 1000
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsUser: 0
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.securityContext
+value: {runAsNonRoot: true, runAsUser: 1000}
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 1000
+`,
+	},
+	{
+		name: "put yaml by path creates the field",
+		config: `
+data:
+  by-path: spec.strategy
+  put-yaml: '{type: Recreate}'
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+`,
+		out: `${filePath}
+fieldPath: spec.strategy
+value: {type: Recreate}
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  replicas: 3
+  strategy:
+    type: Recreate
+`,
+	},
+	{
+		name: "put yaml by path pattern",
+		config: `
+data:
+  by-path: spec.**.resources
+  put-yaml: |
+    limits:
+      cpu: 500m
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: nginx
+          resources: {}
+        - name: sidecar
+          resources:
+            limits:
+              cpu: 100m
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[0].resources
+value: {limits: {cpu: 500m}}
+
+${filePath}
+fieldPath: spec.template.spec.containers[1].resources
+value: {limits: {cpu: 500m}}
+
+Mutated 2 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        resources:
+          limits:
+            cpu: 500m
+      - name: sidecar
+        resources:
+          limits:
+            cpu: 500m
+`,
+	},
+	{
+		name: "append yaml to list",
+		config: `
+data:
+  by-path: spec.template.spec.containers
+  append-yaml: |
+    name: sidecar
+    image: envoy
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers
+value: [{name: nginx, image: nginx}, {name: sidecar, image: envoy}]
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+      - name: sidecar
+        image: envoy
+`,
+	},
+	{
+		name: "append yaml list creates the list",
+		config: `
+data:
+  by-path: spec.args
+  append-yaml: '[--debug, --verbose]'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  image: nginx
+`,
+		out: `${filePath}
+fieldPath: spec.args
+value: [--debug, --verbose]
+
+Mutated 1 field(s)
+`,
+		expectedResources: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  image: nginx
+  args:
+  - --debug
+  - --verbose
+`,
+	},
+	{
+		name: "delete field by path",
+		config: `
+data:
+  by-path: spec.template.spec.containers[*].resources
+  delete: 'true'
+`,
+		input: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: nginx
+          resources:
+            limits:
+              cpu: 500m
+        - name: sidecar
+`,
+		out: `${filePath}
+fieldPath: spec.template.spec.containers[0].resources
+value: {limits: {cpu: 500m}}
+
+Deleted 1 field(s)
+`,
+		expectedResources: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx-deployment
+spec:
+  template:
+    spec:
+      containers:
+      - name: nginx
+      - name: sidecar
+`,
+	},
+	{
+		name: "delete list elements by value",
+		config: `
+data:
+  by-value: --debug
+  delete: 'true'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  args:
+    - --debug
+    - --port=80
+    - --debug
+`,
+		out: `${filePath}
+fieldPath: spec.args[0]
+value: --debug
+
+${filePath}
+fieldPath: spec.args[2]
+value: --debug
+
+Deleted 2 field(s)
+`,
+		expectedResources: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+spec:
+  args:
+  - --port=80
+`,
+	},
+	{
+		name: "only one put operation",
+		config: `
+data:
+  by-path: spec
+  put-value: foo
+  delete: 'true'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `only one of ["put-value", "put-yaml", "append-yaml", "delete"] can be provided`,
+	},
+	{
+		name: "delete without matcher",
+		config: `
+data:
+  delete: 'true'
+`,
+		input: `apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+`,
+		errMsg: `at least one of ["by-path", "by-value", "by-value-regex"] must be provided with "delete"`,
+	},
+}
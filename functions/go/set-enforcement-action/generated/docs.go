@@ -9,9 +9,12 @@ var SetEnforcementActionLong = `
 
 The function will execute as follows:
 
-1. Searches for resources with ` + "`" + `apiVersion: constraints.gatekeeper.sh/v1beta1` + "`" + `
+1. Searches for the constraints, the resources of the ` + "`" + `constraints.gatekeeper.sh` + "`" + `
+   group of any version, and the ` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + ` resources
 2. Applies the enforement action value provided in KptFile to following element:
-   ` + "`" + `spec.enforcementAction` + "`" + `
+   ` + "`" + `spec.enforcementAction` + "`" + ` of the constraints, or ` + "`" + `spec.validationActions` + "`" + ` of
+   the ` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + ` resources, which are set to ` + "`" + `[Deny]` + "`" + `,
+   ` + "`" + `[Warn]` + "`" + ` or ` + "`" + `[Audit]` + "`" + ` for the ` + "`" + `deny` + "`" + `, ` + "`" + `warn` + "`" + ` and ` + "`" + `dryrun` + "`" + ` actions
 
 ` + "`" + `set-enforcement-action` + "`" + ` function can be executed imperatively as follows:
 
@@ -25,4 +28,45 @@ To execute ` + "`" + `set-enforcement-action` + "`" + ` declaratively include th
         configMap:
           enforcementAction: deny
   ...
+
+### Rules
+
+To set different actions on the constraints of a package, e.g. to roll out new
+policies as ` + "`" + `warn` + "`" + ` while keeping the existing ones on ` + "`" + `deny` + "`" + `, the function can
+be configured with a ` + "`" + `SetEnforcementAction` + "`" + ` resource and a list of ` + "`" + `rules` + "`" + `:
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: SetEnforcementAction
+  metadata:
+    name: rollout
+  rules:
+    - enforcementAction: deny
+    - match:
+        labels:
+          rollout: new
+      enforcementAction: warn
+    - match:
+        template: k8srequiredlabels
+        name: require-team
+      scopedEnforcementActions:
+        - action: warn
+          enforcementPoints:
+            - name: validation.gatekeeper.sh
+        - action: deny
+          enforcementPoints:
+            - name: audit.gatekeeper.sh
+
+The ` + "`" + `match` + "`" + ` of a rule selects the constraints and the
+` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + ` resources by ` + "`" + `kind` + "`" + `, ` + "`" + `name` + "`" + `, ` + "`" + `labels` + "`" + ` and
+` + "`" + `template` + "`" + `, the name of the ` + "`" + `ConstraintTemplate` + "`" + ` which is the lower case kind of
+its constraints, or the ` + "`" + `policyName` + "`" + ` of a binding. A constraint must match all the fields of the ` + "`" + `match` + "`" + `, and a
+rule without ` + "`" + `match` + "`" + ` selects all the constraints. The rules are applied in
+order, so that each constraint gets the action of the last rule matching it.
+The constraints which aren't matched by any rule are left unchanged.
+
+A rule with ` + "`" + `scopedEnforcementActions` + "`" + ` sets the actions of specific enforcement
+points, its ` + "`" + `enforcementAction` + "`" + ` is ` + "`" + `scoped` + "`" + `. The ` + "`" + `scopedEnforcementActions` + "`" + ` of
+a constraint are removed by a rule without them. A
+` + "`" + `ValidatingAdmissionPolicyBinding` + "`" + ` matched by a rule with
+` + "`" + `scopedEnforcementActions` + "`" + ` is left unchanged with a warning.
 `
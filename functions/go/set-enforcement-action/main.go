@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"sigs.k8s.io/kustomize/kyaml/fn/framework"
 	"sigs.k8s.io/kustomize/kyaml/fn/framework/command"
@@ -11,8 +12,11 @@ import (
 )
 
 const (
-	policyAPIVersion     = "constraints.gatekeeper.sh/v1beta1"
+	constraintsGroup     = "constraints.gatekeeper.sh"
+	admissionGroup       = "admissionregistration.k8s.io"
+	vapBindingKind       = "ValidatingAdmissionPolicyBinding"
 	enforcementActionKey = "enforcementAction"
+	validationActionsKey = "validationActions"
 	denyActionValue      = "deny"
 	warnActionValue      = "warn"
 	dryRunActionValue    = "dryrun"
@@ -31,6 +35,21 @@ func (fp *SetEnforcementActionProcessor) Process(resourceList *framework.Resourc
 		Name: "set-enforcement-action",
 	}
 
+	if isRules(resourceList.FunctionConfig) {
+		sea, err := decodeRules(resourceList.FunctionConfig)
+		if err != nil {
+			resourceList.Result.Items = getErrorItem(err.Error())
+			return err
+		}
+		items, err := processRules(resourceList.Items, sea)
+		if err != nil {
+			resourceList.Result.Items = getErrorItem(err.Error())
+			return err
+		}
+		resourceList.Result.Items = items
+		return nil
+	}
+
 	// get the enforcementAction value from functionConfig
 	var acn string
 	err := getEnforcementAction(resourceList.FunctionConfig, &acn)
@@ -52,36 +71,22 @@ func (fp *SetEnforcementActionProcessor) Process(resourceList *framework.Resourc
 func processPolicies(resourceList []*yaml.RNode, acn string) ([]framework.ResultItem, error) {
 	var resultItems []framework.ResultItem
 	for _, node := range resourceList {
-		if node.IsNilOrEmpty() {
-			continue
-		}
-
-		//check for resources of type policy
-		metadata, err := node.GetMeta()
+		p, err := getPolicy(node)
 		if err != nil {
 			return nil, err
 		}
-
-		if metadata.Name == "" || metadata.Kind == "" || metadata.APIVersion != policyAPIVersion {
+		if p == nil {
 			continue
 		}
 
-		err = node.PipeE(
-			yaml.LookupCreate(yaml.ScalarNode, "spec"),
-			yaml.SetField("enforcementAction", yaml.NewScalarRNode(acn)))
-		if err != nil {
+		if err := p.setEnforcementAction(node, acn); err != nil {
 			return nil, err
 		}
 
-		itemFilePath := node.GetAnnotations()["internal.config.kubernetes.io/path"]
-		if itemFilePath == "" {
-			itemFilePath = node.GetAnnotations()["config.kubernetes.io/path"]
-		}
-
 		resultItems = append(resultItems, framework.ResultItem{
 			Message: fmt.Sprintf("Policy name: [%s]", node.GetName()),
 			File: framework.File{
-				Path: itemFilePath,
+				Path: filePath(node),
 			},
 			Severity: framework.Info,
 		})
@@ -105,6 +110,83 @@ func processPolicies(resourceList []*yaml.RNode, acn string) ([]framework.Result
 	return resultItems, nil
 }
 
+// policy is a Gatekeeper constraint or a ValidatingAdmissionPolicyBinding
+type policy struct {
+	yaml.ResourceMeta
+
+	// template is the name of the ConstraintTemplate of a constraint, or the
+	// name of the ValidatingAdmissionPolicy of a binding
+	template string
+}
+
+// getPolicy returns the policy of the node, or nil if the node isn't a policy
+func getPolicy(node *yaml.RNode) (*policy, error) {
+	if node.IsNilOrEmpty() {
+		return nil, nil
+	}
+	metadata, err := node.GetMeta()
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Name == "" || metadata.Kind == "" {
+		return nil, nil
+	}
+	group, _ := splitAPIVersion(metadata.APIVersion)
+	switch {
+	case group == constraintsGroup:
+		return &policy{ResourceMeta: metadata, template: strings.ToLower(metadata.Kind)}, nil
+	case group == admissionGroup && metadata.Kind == vapBindingKind:
+		// a binding without policyName doesn't match any template
+		policyName, _ := node.GetString("spec.policyName")
+		return &policy{ResourceMeta: metadata, template: policyName}, nil
+	}
+	return nil, nil
+}
+
+// isBinding returns true if the policy is a ValidatingAdmissionPolicyBinding
+func (p *policy) isBinding() bool {
+	return p.Kind == vapBindingKind
+}
+
+// setEnforcementAction sets the action on the policy, the validationActions of
+// a binding are Deny, Warn or Audit for the deny, warn and dryrun actions
+func (p *policy) setEnforcementAction(node *yaml.RNode, acn string) error {
+	spec, err := node.Pipe(yaml.LookupCreate(yaml.MappingNode, "spec"))
+	if err != nil {
+		return err
+	}
+	if !p.isBinding() {
+		return spec.PipeE(yaml.SetField(enforcementActionKey, yaml.NewScalarRNode(acn)))
+	}
+	actions := yaml.NewListRNode(validationActions[acn])
+	return spec.PipeE(yaml.SetField(validationActionsKey, actions))
+}
+
+// validationActions are the validationActions of a ValidatingAdmissionPolicyBinding
+// for each enforcementAction
+var validationActions = map[string]string{
+	denyActionValue:   "Deny",
+	warnActionValue:   "Warn",
+	dryRunActionValue: "Audit",
+}
+
+// splitAPIVersion returns the group and the version of the apiVersion
+func splitAPIVersion(apiVersion string) (string, string) {
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		return apiVersion[:i], apiVersion[i+1:]
+	}
+	return "", apiVersion
+}
+
+// filePath returns the path of the file of the node
+func filePath(node *yaml.RNode) string {
+	path := node.GetAnnotations()["internal.config.kubernetes.io/path"]
+	if path == "" {
+		path = node.GetAnnotations()["config.kubernetes.io/path"]
+	}
+	return path
+}
+
 // getEnforcementAction gets the value to set for enforcementAction from the functionConfig
 func getEnforcementAction(fc *yaml.RNode, acn *string) error {
 	if len(fc.GetDataMap()) != 1 {
@@ -13,6 +13,7 @@ var tests = []struct {
 	config         string
 	input          string
 	expectedResult []string
+	expected       string
 	errMsg         string
 }{
 	{
@@ -75,6 +76,56 @@ spec:
 			"Policy name: [restrict-clusteradmin-rolebindings]",
 		},
 	},
+	{
+		name: "set v1 policy as warn",
+		input: `apiVersion: constraints.gatekeeper.sh/v1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  enforcementAction: deny
+`,
+		config: `data:
+  enforcementAction: warn
+`,
+		expectedResult: []string{
+			"Number of policies set to [warn]: 1",
+			"Policy name: [require-owner]",
+		},
+		expected: `apiVersion: constraints.gatekeeper.sh/v1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  enforcementAction: warn
+`,
+	},
+	{
+		name: "set ValidatingAdmissionPolicyBinding as dryrun",
+		input: `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: max-replicas
+spec:
+  policyName: max-replicas
+  validationActions: [Deny]
+`,
+		config: `data:
+  enforcementAction: dryrun
+`,
+		expectedResult: []string{
+			"Number of policies set to [dryrun]: 1",
+			"Policy name: [max-replicas]",
+		},
+		expected: `apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: max-replicas
+spec:
+  policyName: max-replicas
+  validationActions: [Audit]
+`,
+	},
 	{
 		name: "no policy found",
 		input: `apiVersion: v1
@@ -156,6 +207,9 @@ func TestPolicyResources(t *testing.T) {
 			for j := range items {
 				require.Equal(t, test.expectedResult[j], items[j].Message)
 			}
+			if test.expected != "" {
+				require.Equal(t, test.expected, policy.MustString())
+			}
 		})
 	}
 }
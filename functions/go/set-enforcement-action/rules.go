@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kustomize/kyaml/fn/framework"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	rulesAPIVersion          = "fn.kpt.dev/v1alpha1"
+	rulesKind                = "SetEnforcementAction"
+	scopedActionValue        = "scoped"
+	scopedEnforcementActions = "scopedEnforcementActions"
+)
+
+// SetEnforcementAction sets the enforcement action of the policies matched by
+// its rules, the rules are applied in order so that a rule overrides the
+// action set by the rules before it
+type SetEnforcementAction struct {
+	yaml.ResourceMeta `yaml:",inline"`
+
+	// Rules are the rules applied in order to each policy
+	Rules []Rule `yaml:"rules,omitempty"`
+}
+
+// Rule sets the enforcement action of the policies it matches
+type Rule struct {
+	// Match selects the policies of the rule, a rule without match selects all
+	// the policies
+	Match Match `yaml:"match,omitempty"`
+
+	// EnforcementAction is the action set on the policies, one of deny, warn
+	// or dryrun, or scoped if the rule has scoped enforcement actions
+	EnforcementAction string `yaml:"enforcementAction,omitempty"`
+
+	// ScopedEnforcementActions are the actions of specific enforcement points
+	ScopedEnforcementActions []ScopedEnforcementAction `yaml:"scopedEnforcementActions,omitempty"`
+}
+
+// Match selects the policies having all the set fields
+type Match struct {
+	// Kind is the kind of the policies
+	Kind string `yaml:"kind,omitempty"`
+
+	// Name is the name of the policies
+	Name string `yaml:"name,omitempty"`
+
+	// Labels are labels which the policies must have
+	Labels map[string]string `yaml:"labels,omitempty"`
+
+	// Template is the name of the ConstraintTemplate of the constraints, which
+	// is the lower case kind of its constraints, or the policyName of the
+	// ValidatingAdmissionPolicyBindings
+	Template string `yaml:"template,omitempty"`
+}
+
+// ScopedEnforcementAction is the action of a policy for enforcement points
+type ScopedEnforcementAction struct {
+	Action            string             `yaml:"action"`
+	EnforcementPoints []EnforcementPoint `yaml:"enforcementPoints"`
+}
+
+// EnforcementPoint is an enforcement point of Gatekeeper, e.g. validation.gatekeeper.sh
+type EnforcementPoint struct {
+	Name string `yaml:"name"`
+}
+
+// isRules returns true if the functionConfig is a SetEnforcementAction resource
+func isRules(fc *yaml.RNode) bool {
+	return fc != nil && fc.GetKind() == rulesKind
+}
+
+// decodeRules decodes and validates the SetEnforcementAction functionConfig
+func decodeRules(fc *yaml.RNode) (*SetEnforcementAction, error) {
+	if fc.GetApiVersion() != rulesAPIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion %q for %s, expected %q",
+			fc.GetApiVersion(), rulesKind, rulesAPIVersion)
+	}
+	s, err := fc.String()
+	if err != nil {
+		return nil, err
+	}
+	sea := &SetEnforcementAction{}
+	d := yaml.NewDecoder(strings.NewReader(s))
+	d.KnownFields(true)
+	if err := d.Decode(sea); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", rulesKind, err)
+	}
+	if len(sea.Rules) == 0 {
+		return nil, fmt.Errorf("%s must have at least one rule", rulesKind)
+	}
+	for i := range sea.Rules {
+		if err := sea.Rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return sea, nil
+}
+
+// validate checks the actions of the rule, the action defaults to scoped if the
+// rule has scoped enforcement actions
+func (r *Rule) validate() error {
+	if len(r.ScopedEnforcementActions) > 0 {
+		if r.EnforcementAction == "" {
+			r.EnforcementAction = scopedActionValue
+		}
+		if r.EnforcementAction != scopedActionValue {
+			return fmt.Errorf("enforcementAction must be [%s] with scopedEnforcementActions", scopedActionValue)
+		}
+		for _, sa := range r.ScopedEnforcementActions {
+			if sa.Action != denyActionValue && sa.Action != warnActionValue {
+				return fmt.Errorf("expected values for the action of scopedEnforcementActions are [%s] or [%s]", denyActionValue, warnActionValue)
+			}
+			if len(sa.EnforcementPoints) == 0 {
+				return fmt.Errorf("scopedEnforcementActions must have at least one enforcement point")
+			}
+		}
+		return nil
+	}
+	if r.EnforcementAction != denyActionValue && r.EnforcementAction != warnActionValue && r.EnforcementAction != dryRunActionValue {
+		return fmt.Errorf("expected values for enforcementAction are [%s] or [%s] or [%s]", denyActionValue, warnActionValue, dryRunActionValue)
+	}
+	return nil
+}
+
+// Matches returns true if the policy has all the fields of the match
+func (m *Match) Matches(p *policy) bool {
+	if m.Kind != "" && m.Kind != p.Kind {
+		return false
+	}
+	if m.Name != "" && m.Name != p.Name {
+		return false
+	}
+	if m.Template != "" && m.Template != p.template {
+		return false
+	}
+	for k, v := range m.Labels {
+		if value, found := p.Labels[k]; !found || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// apply sets the actions of the rule on the policy, the scoped enforcement
+// actions of a constraint are removed if the rule has none
+func (r *Rule) apply(node *yaml.RNode, p *policy) error {
+	if err := p.setEnforcementAction(node, r.EnforcementAction); err != nil {
+		return err
+	}
+	if p.isBinding() {
+		return nil
+	}
+	spec, err := node.Pipe(yaml.Lookup("spec"))
+	if err != nil {
+		return err
+	}
+	if len(r.ScopedEnforcementActions) == 0 {
+		return spec.PipeE(yaml.Clear(scopedEnforcementActions))
+	}
+	content, err := yaml.Marshal(r.ScopedEnforcementActions)
+	if err != nil {
+		return err
+	}
+	actions, err := yaml.Parse(string(content))
+	if err != nil {
+		return err
+	}
+	return spec.PipeE(yaml.SetField(scopedEnforcementActions, actions))
+}
+
+// processRules applies the rules to the policies in the package, each policy
+// gets the actions of the last rule matching it
+func processRules(nodes []*yaml.RNode, sea *SetEnforcementAction) ([]framework.ResultItem, error) {
+	var resultItems, skipped []framework.ResultItem
+	matched := make([]bool, len(sea.Rules))
+	for _, node := range nodes {
+		p, err := getPolicy(node)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue
+		}
+
+		last := -1
+		for i := range sea.Rules {
+			if sea.Rules[i].Match.Matches(p) {
+				matched[i] = true
+				last = i
+			}
+		}
+		if last < 0 {
+			continue
+		}
+		rule := &sea.Rules[last]
+		if p.isBinding() && len(rule.ScopedEnforcementActions) > 0 {
+			skipped = append(skipped, framework.ResultItem{
+				Message: fmt.Sprintf("Policy name: [%s] is a %s which doesn't support the scopedEnforcementActions of rule %d",
+					p.Name, vapBindingKind, last+1),
+				File: framework.File{
+					Path: filePath(node),
+				},
+				Severity: framework.Warning,
+			})
+			continue
+		}
+		if err := rule.apply(node, p); err != nil {
+			return nil, err
+		}
+		resultItems = append(resultItems, framework.ResultItem{
+			Message: fmt.Sprintf("Policy name: [%s] set to [%s] by rule %d", p.Name, rule.EnforcementAction, last+1),
+			File: framework.File{
+				Path: filePath(node),
+			},
+			Severity: framework.Info,
+		})
+	}
+
+	if len(resultItems) > 0 {
+		resultItems = append([]framework.ResultItem{{
+			Severity: framework.Info,
+			Message:  fmt.Sprintf("Number of policies set by the rules: %d", len(resultItems)),
+		}}, resultItems...)
+	}
+	resultItems = append(resultItems, skipped...)
+	for i := range sea.Rules {
+		if !matched[i] {
+			resultItems = append(resultItems, framework.ResultItem{
+				Message:  fmt.Sprintf("Found no policy matching rule %d", i+1),
+				Severity: framework.Warning,
+			})
+		}
+	}
+	return resultItems, nil
+}
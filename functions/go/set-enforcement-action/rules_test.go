@@ -0,0 +1,291 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const rulesInput = `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRestrictRoleBindings
+metadata:
+  name: restrict-clusteradmin-rolebindings
+spec:
+  enforcementAction: dryrun
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+  labels:
+    rollout: new
+spec:
+  enforcementAction: deny
+  scopedEnforcementActions:
+  - action: deny
+    enforcementPoints:
+    - name: audit.gatekeeper.sh
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-team
+spec:
+  enforcementAction: deny
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`
+
+func TestProcessRules(t *testing.T) {
+	var ruleTests = []struct {
+		name           string
+		input          string
+		config         string
+		expectedResult []string
+		expected       string
+		errMsg         string
+	}{
+		{
+			name: "later rules override earlier rules",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- enforcementAction: deny
+- match:
+    labels:
+      rollout: new
+  enforcementAction: warn
+- match:
+    template: k8srequiredlabels
+    name: require-team
+  scopedEnforcementActions:
+  - action: warn
+    enforcementPoints:
+    - name: validation.gatekeeper.sh
+  - action: deny
+    enforcementPoints:
+    - name: audit.gatekeeper.sh
+- match:
+    kind: K8sAllowedRepos
+  enforcementAction: dryrun
+`,
+			expectedResult: []string{
+				"Number of policies set by the rules: 3",
+				"Policy name: [restrict-clusteradmin-rolebindings] set to [deny] by rule 1",
+				"Policy name: [require-owner] set to [warn] by rule 2",
+				"Policy name: [require-team] set to [scoped] by rule 3",
+				"Found no policy matching rule 4",
+			},
+			expected: `apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRestrictRoleBindings
+metadata:
+  name: restrict-clusteradmin-rolebindings
+spec:
+  enforcementAction: deny
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+  labels:
+    rollout: new
+spec:
+  enforcementAction: warn
+---
+apiVersion: constraints.gatekeeper.sh/v1beta1
+kind: K8sRequiredLabels
+metadata:
+  name: require-team
+spec:
+  enforcementAction: scoped
+  scopedEnforcementActions:
+  - action: warn
+    enforcementPoints:
+    - name: validation.gatekeeper.sh
+  - action: deny
+    enforcementPoints:
+    - name: audit.gatekeeper.sh
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+`,
+		},
+		{
+			name: "v1 constraints and ValidatingAdmissionPolicyBindings",
+			input: `apiVersion: constraints.gatekeeper.sh/v1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  enforcementAction: deny
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: max-replicas
+spec:
+  policyName: max-replicas
+  validationActions: [Deny]
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: require-owner
+spec:
+  policyName: k8srequiredlabels
+  validationActions: [Deny]
+`,
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- match:
+    template: max-replicas
+  enforcementAction: warn
+- match:
+    template: k8srequiredlabels
+  scopedEnforcementActions:
+  - action: warn
+    enforcementPoints:
+    - name: validation.gatekeeper.sh
+`,
+			expectedResult: []string{
+				"Number of policies set by the rules: 2",
+				"Policy name: [require-owner] set to [scoped] by rule 2",
+				"Policy name: [max-replicas] set to [warn] by rule 1",
+				"Policy name: [require-owner] is a ValidatingAdmissionPolicyBinding which doesn't support the scopedEnforcementActions of rule 2",
+			},
+			expected: `apiVersion: constraints.gatekeeper.sh/v1
+kind: K8sRequiredLabels
+metadata:
+  name: require-owner
+spec:
+  enforcementAction: scoped
+  scopedEnforcementActions:
+  - action: warn
+    enforcementPoints:
+    - name: validation.gatekeeper.sh
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: max-replicas
+spec:
+  policyName: max-replicas
+  validationActions: [Warn]
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: require-owner
+spec:
+  policyName: k8srequiredlabels
+  validationActions: [Deny]
+`,
+		},
+		{
+			name: "no rules",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+`,
+			errMsg: "SetEnforcementAction must have at least one rule",
+		},
+		{
+			name: "unknown field",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- match:
+    namespace: default
+  enforcementAction: warn
+`,
+			errMsg: "invalid SetEnforcementAction: yaml: unmarshal errors:\n  line 7: field namespace not found in type main.Match",
+		},
+		{
+			name: "incorrect enforcementAction",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- enforcementAction: dry-run
+`,
+			errMsg: "rule 1: expected values for enforcementAction are [deny] or [warn] or [dryrun]",
+		},
+		{
+			name: "enforcementAction with scopedEnforcementActions",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- enforcementAction: deny
+  scopedEnforcementActions:
+  - action: warn
+    enforcementPoints:
+    - name: validation.gatekeeper.sh
+`,
+			errMsg: "rule 1: enforcementAction must be [scoped] with scopedEnforcementActions",
+		},
+		{
+			name: "unsupported apiVersion",
+			config: `apiVersion: fn.kpt.dev/v1beta1
+kind: SetEnforcementAction
+metadata:
+  name: rollout
+rules:
+- enforcementAction: deny
+`,
+			errMsg: `unsupported apiVersion "fn.kpt.dev/v1beta1" for SetEnforcementAction, expected "fn.kpt.dev/v1alpha1"`,
+		},
+	}
+
+	for i := range ruleTests {
+		test := ruleTests[i]
+		t.Run(test.name, func(t *testing.T) {
+			fcNode, err := yaml.Parse(test.config)
+			require.NoError(t, err)
+			require.True(t, isRules(fcNode))
+			sea, err := decodeRules(fcNode)
+			if test.errMsg != "" {
+				require.EqualError(t, err, test.errMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			input := test.input
+			if input == "" {
+				input = rulesInput
+			}
+			nodes, err := kio.FromBytes([]byte(input))
+			require.NoError(t, err)
+			items, err := processRules(nodes, sea)
+			require.NoError(t, err)
+
+			var messages []string
+			for _, item := range items {
+				messages = append(messages, item.Message)
+			}
+			assert.Equal(t, test.expectedResult, messages)
+
+			output, err := kio.StringAll(nodes)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, output)
+		})
+	}
+}
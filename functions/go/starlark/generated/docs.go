@@ -84,6 +84,28 @@ long as it can be represented in yaml.
 In the example above, the script accesses the ` + "`" + `toMatch` + "`" + ` parameters
 using ` + "`" + `ctx.resource_list["functionConfig"]["params"]["toMatch"]` + "`" + `.
 
+The script runs in a sandbox with the following limits, which a ` + "`" + `StarlarkRun` + "`" + `
+can change. A ` + "`" + `ConfigMap` + "`" + ` always uses the defaults.
+
+| Field               | Default    | Description |
+|---------------------|------------|-------------|
+| ` + "`" + `allowNetwork` + "`" + `      | ` + "`" + `false` + "`" + `    | Allows the script to load the libraries accessing the network, i.e. [http] |
+| ` + "`" + `maxExecutionSteps` + "`" + ` | ` + "`" + `10000000` + "`" + ` | The maximum number of execution steps of the script |
+| ` + "`" + `timeout` + "`" + `           | ` + "`" + `30s` + "`" + `      | The maximum duration of the script, e.g. ` + "`" + `1m` + "`" + ` |
+
+A script exceeding a limit fails the function with an error result, e.g.
+` + "`" + `Starlark computation cancelled: exceeded the timeout of 30s` + "`" + `.
+
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: StarlarkRun
+  metadata:
+    name: fetch-defaults
+  allowNetwork: true
+  timeout: 1m
+  source: |
+    load("http.star", "http")
+    ...
+
 There are 2 ways to run the function declaratively.
 
 - Have your ` + "`" + `Kptfile` + "`" + ` with the inline ` + "`" + `ConfigMap` + "`" + ` as the ` + "`" + `functionConfig` + "`" + `.
@@ -119,11 +141,11 @@ Here's what you can do in the Starlark script:
 - Read the OpenAPI schema. e.g. ` + "`" + `ctx.open_api["definitions"]["io.k8s.api.apps.v1.Deployment"]` + "`" + `
 - Return an error using [` + "`" + `fail` + "`" + `][fail].
 - Write error message to stderr using [` + "`" + `print` + "`" + `][print]
+- Write results to ` + "`" + `ctx.results` + "`" + `, an alias of ` + "`" + `ctx.resource_list["results"]` + "`" + `.
+  e.g. ` + "`" + `ctx.results.append({"severity": "warning", "message": "too few replicas"})` + "`" + `
 
 Here's what you currently cannot do in the Starlark script:
 
-- We don't support the ` + "`" + `results` + "`" + ` field yet, i.e. if you write some results in
-  ` + "`" + `ctx.resource_list["results"]` + "`" + `, they will be ignored.
 - While Starlark programs don't support working with yaml comments on resources,
   kpt will attempt to retain comments by copying them from the function inputs
   to the function outputs.
@@ -139,9 +161,29 @@ In the standard Starlark, a [for loop] is permitted only within a function
 definition. But in the starlark function, you can conveniently use ` + "`" + `for` + "`" + `
 statement at the top-level.
 
+Results:
+
+A result in ` + "`" + `ctx.results` + "`" + ` is a dict with the fields of the [results] of the
+function spec: ` + "`" + `message` + "`" + ` is required, ` + "`" + `severity` + "`" + ` is one of ` + "`" + `error` + "`" + `, ` + "`" + `warning` + "`" + `
+or ` + "`" + `info` + "`" + ` and defaults to ` + "`" + `info` + "`" + `, and ` + "`" + `resourceRef` + "`" + `, ` + "`" + `field` + "`" + `, ` + "`" + `file` + "`" + ` and ` + "`" + `tags` + "`" + `
+are optional:
+
+  ctx.results.append({
+    "severity": "error",
+    "message": "replicas must be at least 3",
+    "resourceRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "nginx"},
+    "field": {"path": "spec.replicas", "currentValue": 1, "proposedValue": 3},
+    "file": {"path": "deployment.yaml"},
+  })
+
+The results are reported along with the resources, and the function fails if
+any of them is an ` + "`" + `error` + "`" + `. The [krmfn](#krmfn) ` + "`" + `result` + "`" + ` helper fills the
+` + "`" + `resourceRef` + "`" + ` and ` + "`" + `file` + "`" + ` from a resource.
+
 Libraries:
 
-We support the following [Starlib libraries]:
+We support the following [Starlib libraries], [http] can only be loaded when
+` + "`" + `allowNetwork` + "`" + ` is ` + "`" + `true` + "`" + `:
 
 | Name               | How to load                            | Example |
 |--------------------|----------------------------------------|---------|
@@ -160,6 +202,43 @@ We support the following [Starlib libraries]:
 | [xlsx]             | load('xlsx.star', 'xlsx')              | [example](https://github.com/qri-io/starlib/blob/master/xlsx/testdata/test.star)            |
 | [zipfile]          | load('zipfile.star', 'ZipFile')        | [example](https://github.com/qri-io/starlib/blob/master/zipfile/testdata/test.star)         |
 
+krmfn:
+
+The ` + "`" + `krmfn` + "`" + ` library provides helpers for the resources of
+` + "`" + `ctx.resource_list["items"]` + "`" + `, they work on the resources in place:
+
+  load("krmfn.star", "krmfn")
+  
+  for resource in ctx.resource_list["items"]:
+    if krmfn.match_gvk(resource, "apps/v1", "Deployment") and krmfn.match_labels(resource, "tier=frontend"):
+      krmfn.set(resource, "spec.replicas", 3)
+      krmfn.set_label(resource, "owner", "platform")
+      krmfn.result("info", "scaled to 3 replicas", resource, "spec.replicas")
+
+| Function                                                   | Description |
+|------------------------------------------------------------|-------------|
+| ` + "`" + `match_gvk(resource, apiVersion, kind)` + "`" + `                    | Whether the resource has the apiVersion and kind, an empty group, version or kind matches any value |
+| ` + "`" + `match_name(resource, name)` + "`" + `                               | Whether the resource has the name |
+| ` + "`" + `match_namespace(resource, namespace)` + "`" + `                     | Whether the resource has the namespace |
+| ` + "`" + `match_labels(resource, selector)` + "`" + `                         | Whether the labels of the resource match the selector, a string e.g. ` + "`" + `"app=nginx,tier in (frontend)"` + "`" + ` or a label selector dict with ` + "`" + `matchLabels` + "`" + ` and ` + "`" + `matchExpressions` + "`" + ` |
+| ` + "`" + `get(resource, path, default=None)` + "`" + `                        | The field at the path, or the default if it's missing |
+| ` + "`" + `set(resource, path, value)` + "`" + `                               | Sets the field at the path, the missing dicts of the path are created |
+| ` + "`" + `delete(resource, path)` + "`" + `                                   | Deletes the field at the path, and returns whether it was found |
+| ` + "`" + `get_label(resource, key, default=None)` + "`" + `                   | The value of the label, or the default if it's missing |
+| ` + "`" + `set_label(resource, key, value)` + "`" + `                          | Sets the label |
+| ` + "`" + `delete_label(resource, key)` + "`" + `                              | Deletes the label, and returns whether it was found |
+| ` + "`" + `get_annotation(resource, key, default=None)` + "`" + `              | The value of the annotation, or the default if it's missing |
+| ` + "`" + `set_annotation(resource, key, value)` + "`" + `                     | Sets the annotation |
+| ` + "`" + `delete_annotation(resource, key)` + "`" + `                         | Deletes the annotation, and returns whether it was found |
+| ` + "`" + `is_cluster_scoped(resource)` + "`" + `                              | Whether the resource is cluster scoped, the scope of unknown kinds is guessed from their namespace |
+| ` + "`" + `set_namespace(resource, namespace)` + "`" + `                       | Sets the namespace of a namespace scoped resource, and returns whether it was set |
+| ` + "`" + `origin_id(resource)` + "`" + `                                      | The id of the upstream origin of the resource, ` + "`" + `group\|kind\|namespace\|name` + "`" + ` |
+| ` + "`" + `result(severity, message, resource=None, field=None)` + "`" + `     | Appends a result to ` + "`" + `ctx.results` + "`" + `, the severity is one of ` + "`" + `error` + "`" + `, ` + "`" + `warning` + "`" + ` or ` + "`" + `info` + "`" + ` |
+
+A path is either a dot separated string, e.g. ` + "`" + `"spec.template.spec.containers.0.image"` + "`" + `
+where the integers are the indexes of the lists, or a list of keys, e.g.
+` + "`" + `["metadata", "annotations", "example.com/owner"]` + "`" + ` for the keys containing dots.
+
 ### Debugging
 
 It is possible to debug the ` + "`" + `starlark` + "`" + ` functions using [` + "`" + `print` + "`" + `][print].
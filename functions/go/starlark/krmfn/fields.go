@@ -0,0 +1,222 @@
+package krmfn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// parsePath returns the keys of a path, which is either a dot separated string
+// e.g. "spec.replicas", or a list of keys e.g. ["metadata", "labels", "app.kubernetes.io/name"]
+// for the keys containing dots. The indexes of the lists are integers.
+func parsePath(b *starlark.Builtin, path starlark.Value) ([]starlark.Value, error) {
+	var keys []starlark.Value
+	switch p := path.(type) {
+	case starlark.String:
+		if p == "" {
+			return nil, fmt.Errorf("%s: path must not be empty", b.Name())
+		}
+		for _, key := range strings.Split(string(p), ".") {
+			if i, err := strconv.Atoi(key); err == nil {
+				keys = append(keys, starlark.MakeInt(i))
+				continue
+			}
+			keys = append(keys, starlark.String(key))
+		}
+	case *starlark.List, starlark.Tuple:
+		iter := starlark.Iterate(p)
+		defer iter.Done()
+		var key starlark.Value
+		for iter.Next(&key) {
+			switch key.(type) {
+			case starlark.String, starlark.Int:
+				keys = append(keys, key)
+			default:
+				return nil, fmt.Errorf("%s: the keys of a path must be strings or ints, got %s", b.Name(), key.Type())
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("%s: path must not be empty", b.Name())
+		}
+	default:
+		return nil, fmt.Errorf("%s: path must be a string or a list, got %s", b.Name(), path.Type())
+	}
+	return keys, nil
+}
+
+// listIndex returns the index of the key in the list, or false if the key
+// isn't an index of the list
+func listIndex(l *starlark.List, key starlark.Value) (int, bool) {
+	k, ok := key.(starlark.Int)
+	if !ok {
+		return 0, false
+	}
+	i, ok := k.Int64()
+	if !ok || i < 0 || int(i) >= l.Len() {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// lookup returns the value at the keys of the value
+func lookup(v starlark.Value, keys []starlark.Value) (starlark.Value, bool, error) {
+	for _, key := range keys {
+		switch c := v.(type) {
+		case *starlark.Dict:
+			value, found, err := c.Get(key)
+			if err != nil || !found {
+				return nil, false, err
+			}
+			v = value
+		case *starlark.List:
+			i, ok := listIndex(c, key)
+			if !ok {
+				return nil, false, nil
+			}
+			v = c.Index(i)
+		default:
+			return nil, false, nil
+		}
+	}
+	return v, true, nil
+}
+
+// get returns the field at the path of the resource, or the default if the
+// field is missing
+func get(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource, path starlark.Value
+	var def starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "path", &path, "default?", &def); err != nil {
+		return nil, err
+	}
+	keys, err := parsePath(b, path)
+	if err != nil {
+		return nil, err
+	}
+	v, found, err := lookup(resource, keys)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return def, nil
+	}
+	return v, nil
+}
+
+// set sets the field at the path of the resource, the missing dicts of the
+// path are created
+func set(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource, path, value starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "path", &path, "value", &value); err != nil {
+		return nil, err
+	}
+	keys, err := parsePath(b, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := setField(b, resource, keys, value); err != nil {
+		return nil, err
+	}
+	return starlark.None, nil
+}
+
+func setField(b *starlark.Builtin, v starlark.Value, keys []starlark.Value, value starlark.Value) error {
+	for i, key := range keys {
+		last := i == len(keys)-1
+		switch c := v.(type) {
+		case *starlark.Dict:
+			if last {
+				return c.SetKey(key, value)
+			}
+			next, found, err := c.Get(key)
+			if err != nil {
+				return err
+			}
+			if !found || next == starlark.None {
+				next = starlark.NewDict(1)
+				if err := c.SetKey(key, next); err != nil {
+					return err
+				}
+			}
+			v = next
+		case *starlark.List:
+			index, ok := listIndex(c, key)
+			if !ok {
+				return fmt.Errorf("%s: index %s out of range of the list at %s", b.Name(), key, pathString(keys[:i]))
+			}
+			if last {
+				return c.SetIndex(index, value)
+			}
+			v = c.Index(index)
+		default:
+			return fmt.Errorf("%s: %s at %s is not a dict or a list", b.Name(), v.Type(), pathString(keys[:i]))
+		}
+	}
+	return nil
+}
+
+// del deletes the field at the path of the resource, and returns whether the
+// field was found
+func del(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource, path starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "path", &path); err != nil {
+		return nil, err
+	}
+	keys, err := parsePath(b, path)
+	if err != nil {
+		return nil, err
+	}
+	return deleteField(resource, keys)
+}
+
+func deleteField(v starlark.Value, keys []starlark.Value) (starlark.Value, error) {
+	parent, found, err := lookup(v, keys[:len(keys)-1])
+	if err != nil || !found {
+		return starlark.False, err
+	}
+	key := keys[len(keys)-1]
+	switch c := parent.(type) {
+	case *starlark.Dict:
+		_, found, err := c.Delete(key)
+		return starlark.Bool(found), err
+	case *starlark.List:
+		index, ok := listIndex(c, key)
+		if !ok {
+			return starlark.False, nil
+		}
+		elems := make([]starlark.Value, 0, c.Len()-1)
+		for i := 0; i < c.Len(); i++ {
+			if i != index {
+				elems = append(elems, c.Index(i))
+			}
+		}
+		if err := c.Clear(); err != nil {
+			return nil, err
+		}
+		for _, e := range elems {
+			if err := c.Append(e); err != nil {
+				return nil, err
+			}
+		}
+		return starlark.True, nil
+	}
+	return starlark.False, nil
+}
+
+// pathString returns the dot separated path of the keys
+func pathString(keys []starlark.Value) string {
+	var s []string
+	for _, key := range keys {
+		if k, ok := key.(starlark.String); ok {
+			s = append(s, string(k))
+			continue
+		}
+		s = append(s, key.String())
+	}
+	if len(s) == 0 {
+		return "the root"
+	}
+	return strings.Join(s, ".")
+}
@@ -1,6 +1,8 @@
 package krmfn
 
 import (
+	"fmt"
+
 	"github.com/kptdev/krm-functions-sdk/go/fn"
 	"go.starlark.net/starlark"
 	"go.starlark.net/starlarkstruct"
@@ -12,52 +14,109 @@ const ModuleName = "krmfn.star"
 var Module = &starlarkstruct.Module{
 	Name: "krmfn",
 	Members: starlark.StringDict{
-		"match_gvk":       starlark.NewBuiltin("match_gvk", matchGVK),
-		"match_name":      starlark.NewBuiltin("match_name", matchName),
-		"match_namespace": starlark.NewBuiltin("match_namespace", matchNamespace),
+		"match_gvk":         starlark.NewBuiltin("match_gvk", matchGVK),
+		"match_name":        starlark.NewBuiltin("match_name", matchName),
+		"match_namespace":   starlark.NewBuiltin("match_namespace", matchNamespace),
+		"match_labels":      starlark.NewBuiltin("match_labels", matchLabels),
+		"get":               starlark.NewBuiltin("get", get),
+		"set":               starlark.NewBuiltin("set", set),
+		"delete":            starlark.NewBuiltin("delete", del),
+		"get_label":         starlark.NewBuiltin("get_label", getMetadataField("labels")),
+		"set_label":         starlark.NewBuiltin("set_label", setMetadataField("labels")),
+		"delete_label":      starlark.NewBuiltin("delete_label", deleteMetadataField("labels")),
+		"get_annotation":    starlark.NewBuiltin("get_annotation", getMetadataField("annotations")),
+		"set_annotation":    starlark.NewBuiltin("set_annotation", setMetadataField("annotations")),
+		"delete_annotation": starlark.NewBuiltin("delete_annotation", deleteMetadataField("annotations")),
+		"is_cluster_scoped": starlark.NewBuiltin("is_cluster_scoped", isClusterScoped),
+		"set_namespace":     starlark.NewBuiltin("set_namespace", setNamespace),
+		"origin_id":         starlark.NewBuiltin("origin_id", originID),
+		"result":            starlark.NewBuiltin("result", result),
 	},
 }
 
-func matchGVK(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var resourceList starlark.Value
+// resourceDict returns the resource as a dict, the resources of the
+// resource list are dicts
+func resourceDict(b *starlark.Builtin, v starlark.Value) (*starlark.Dict, error) {
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a resource dict, got %s", b.Name(), v.Type())
+	}
+	return d, nil
+}
+
+// getString returns the string at the path of the resource, or an empty string
+// if the field is missing or isn't a string
+func getString(resource *starlark.Dict, path ...string) string {
+	var v starlark.Value = resource
+	for _, key := range path {
+		d, ok := v.(*starlark.Dict)
+		if !ok {
+			return ""
+		}
+		value, found, err := d.Get(starlark.String(key))
+		if err != nil || !found {
+			return ""
+		}
+		v = value
+	}
+	s, ok := v.(starlark.String)
+	if !ok {
+		return ""
+	}
+	return string(s)
+}
+
+func matchGVK(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
 	var apiVersion, kind string
-	if err := starlark.UnpackPositionalArgs("match_gvk", args, kwargs, 3,
-		&resourceList, &apiVersion, &kind); err != nil {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 3,
+		&resource, &apiVersion, &kind); err != nil {
 		return nil, err
 	}
 	gv, err := schema.ParseGroupVersion(apiVersion)
 	if err != nil {
 		return nil, err
 	}
-	obj, err := fn.ParseKubeObject([]byte(resourceList.String()))
+	d, err := resourceDict(b, resource)
 	if err != nil {
 		return nil, err
 	}
-	return starlark.Bool(obj.IsGVK(gv.Group, gv.Version, kind)), nil
+	// the empty fields match any value, like fn.KubeObject.IsGVK
+	group, version := fn.ParseGroupVersion(getString(d, "apiVersion"))
+	resourceKind := getString(d, "kind")
+	switch {
+	case resourceKind != "" && kind != "" && resourceKind != kind:
+		return starlark.False, nil
+	case group != "" && gv.Group != "" && group != gv.Group:
+		return starlark.False, nil
+	case version != "" && gv.Version != "" && version != gv.Version:
+		return starlark.False, nil
+	}
+	return starlark.True, nil
 }
 
-func matchName(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var resourceList starlark.Value
+func matchName(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
 	var name string
-	if err := starlark.UnpackPositionalArgs("match_name", args, kwargs, 2, &resourceList, &name); err != nil {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &resource, &name); err != nil {
 		return nil, err
 	}
-	obj, err := fn.ParseKubeObject([]byte(resourceList.String()))
+	d, err := resourceDict(b, resource)
 	if err != nil {
 		return nil, err
 	}
-	return starlark.Bool(obj.GetName() == name), nil
+	return starlark.Bool(getString(d, "metadata", "name") == name), nil
 }
 
-func matchNamespace(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var resourceList starlark.Value
+func matchNamespace(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
 	var namespace string
-	if err := starlark.UnpackPositionalArgs("match_namespace", args, kwargs, 2, &resourceList, &namespace); err != nil {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &resource, &namespace); err != nil {
 		return nil, err
 	}
-	obj, err := fn.ParseKubeObject([]byte(resourceList.String()))
+	d, err := resourceDict(b, resource)
 	if err != nil {
 		return nil, err
 	}
-	return starlark.Bool(obj.GetNamespace() == namespace), nil
+	return starlark.Bool(getString(d, "metadata", "namespace") == namespace), nil
 }
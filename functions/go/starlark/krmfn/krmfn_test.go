@@ -0,0 +1,193 @@
+package krmfn
+
+import (
+	"testing"
+
+	kstarlark "github.com/kptdev/krm-functions-catalog/functions/go/starlark/third_party/sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"github.com/qri-io/starlib/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const resourceList = `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: nginx
+    namespace: default
+    labels:
+      app: nginx
+      tier: frontend
+    annotations:
+      internal.config.kubernetes.io/path: deployment.yaml
+      internal.config.kubernetes.io/index: "0"
+  spec:
+    replicas: 3
+    template:
+      spec:
+        containers:
+        - name: nginx
+          image: nginx:1.25
+        - name: sidecar
+          image: envoy
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: ClusterRole
+  metadata:
+    name: reader
+    annotations:
+      internal.kpt.dev/upstream-identifier: rbac.authorization.k8s.io|ClusterRole|~C|viewer
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: config
+`
+
+func TestModule(t *testing.T) {
+	testcases := []struct {
+		name     string
+		script   string
+		expected string
+		errMsg   string
+	}{
+		{
+			name: "matchers",
+			script: `out = [
+  krmfn.match_gvk(deployment, "apps/v1", "Deployment"),
+  krmfn.match_gvk(deployment, "apps/v1beta1", "Deployment"),
+  krmfn.match_gvk(deployment, "", "Deployment"),
+  krmfn.match_name(deployment, "nginx"),
+  krmfn.match_namespace(deployment, "kube-system"),
+  krmfn.match_labels(deployment, "app=nginx,tier in (frontend, backend)"),
+  krmfn.match_labels(deployment, {"matchLabels": {"app": "nginx"}, "matchExpressions": [{"key": "tier", "operator": "NotIn", "values": ["frontend"]}]}),
+  krmfn.match_labels(configmap, ""),
+]`,
+			expected: `[True, False, True, True, False, True, False, True]`,
+		},
+		{
+			name: "get",
+			script: `out = [
+  krmfn.get(deployment, "spec.replicas"),
+  krmfn.get(deployment, "spec.template.spec.containers.1.image"),
+  krmfn.get(deployment, ["spec", "template", "spec", "containers", 0, "name"]),
+  krmfn.get(deployment, "spec.missing.field", "default"),
+  krmfn.get(deployment, "spec.template.spec.containers.2"),
+]`,
+			expected: `[3, "envoy", "nginx", "default", None]`,
+		},
+		{
+			name: "set and delete",
+			script: `krmfn.set(deployment, "spec.replicas", 5)
+krmfn.set(deployment, "spec.strategy.type", "Recreate")
+krmfn.set(deployment, "spec.template.spec.containers.0.image", "nginx:1.27")
+deleted = [
+  krmfn.delete(deployment, "spec.template.spec.containers.1"),
+  krmfn.delete(deployment, "spec.missing"),
+]
+containers = deployment["spec"]["template"]["spec"]["containers"]
+out = [deployment["spec"]["replicas"], deployment["spec"]["strategy"], len(containers), containers[0]["image"], deleted]`,
+			expected: `[5, {"type": "Recreate"}, 1, "nginx:1.27", [True, False]]`,
+		},
+		{
+			name:   "set in a scalar",
+			script: `krmfn.set(deployment, "spec.replicas.value", 5)`,
+			errMsg: "set: int at spec.replicas is not a dict or a list",
+		},
+		{
+			name:   "set out of range",
+			script: `krmfn.set(deployment, "spec.template.spec.containers.5.image", "nginx")`,
+			errMsg: "set: index 5 out of range of the list at spec.template.spec.containers",
+		},
+		{
+			name: "labels and annotations",
+			script: `krmfn.set_label(configmap, "app.kubernetes.io/name", "config")
+krmfn.set_annotation(deployment, "owner", "platform")
+out = [
+  krmfn.get_label(deployment, "app"),
+  krmfn.get_label(configmap, "app.kubernetes.io/name"),
+  krmfn.get_annotation(configmap, "owner", "nobody"),
+  krmfn.get_annotation(deployment, "owner"),
+  krmfn.delete_label(deployment, "tier"),
+  krmfn.delete_annotation(configmap, "owner"),
+  deployment["metadata"]["labels"],
+]`,
+			expected: `["nginx", "config", "nobody", "platform", True, False, {"app": "nginx"}]`,
+		},
+		{
+			name:   "upstream-identifier annotation",
+			script: `krmfn.set_annotation(deployment, "internal.kpt.dev/upstream-identifier", "apps|Deployment|default|foo")`,
+			errMsg: "set_annotation: the internal.kpt.dev/upstream-identifier annotation is managed by kpt",
+		},
+		{
+			name: "scope and namespace",
+			script: `out = [
+  krmfn.is_cluster_scoped(deployment),
+  krmfn.is_cluster_scoped(clusterrole),
+  krmfn.set_namespace(clusterrole, "foo"),
+  krmfn.set_namespace(configmap, "foo"),
+  configmap["metadata"]["namespace"],
+  "namespace" in clusterrole["metadata"],
+]`,
+			expected: `[False, True, False, True, "foo", False]`,
+		},
+		{
+			name: "origin id",
+			script: `out = [
+  krmfn.origin_id(deployment),
+  krmfn.origin_id(clusterrole),
+  krmfn.origin_id(configmap),
+]`,
+			expected: `["apps|Deployment|default|nginx", "rbac.authorization.k8s.io|ClusterRole|~C|viewer", "|ConfigMap|default|config"]`,
+		},
+		{
+			name: "results",
+			script: `krmfn.result("warning", "too many replicas", deployment, "spec.replicas")
+krmfn.result(severity = "info", message = "done")
+out = resource_list["results"]`,
+			expected: `[{"severity": "warning", "message": "too many replicas", "resourceRef": {"apiVersion": "apps/v1", "kind": "Deployment", "name": "nginx", "namespace": "default"}, "file": {"path": "deployment.yaml", "index": 0}, "field": {"path": "spec.replicas"}}, {"severity": "info", "message": "done"}]`,
+		},
+		{
+			name:   "invalid severity",
+			script: `krmfn.result("fatal", "oops")`,
+			errMsg: `result: severity must be one of error, warning or info, got "fatal"`,
+		},
+		{
+			name:   "not a resource",
+			script: `krmfn.match_name("nginx", "nginx")`,
+			errMsg: "match_name: expected a resource dict, got string",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			rn, err := yaml.Parse(resourceList)
+			require.NoError(t, err)
+			m, err := rn.Map()
+			require.NoError(t, err)
+			rl, err := util.Marshal(m)
+			require.NoError(t, err)
+			items, _, err := rl.(*starlark.Dict).Get(starlark.String("items"))
+			require.NoError(t, err)
+
+			thread := &starlark.Thread{Name: tc.name}
+			thread.SetLocal(kstarlark.ResourceListLocal, rl)
+			globals, err := starlark.ExecFile(thread, tc.name, tc.script, starlark.StringDict{
+				"krmfn":         Module,
+				"resource_list": rl,
+				"deployment":    items.(*starlark.List).Index(0),
+				"clusterrole":   items.(*starlark.List).Index(1),
+				"configmap":     items.(*starlark.List).Index(2),
+			})
+			if tc.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, globals["out"].String())
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package krmfn
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/kustomize/kyaml/openapi"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// getMetadataField returns the builtin getting a label or an annotation
+func getMetadataField(field string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var resource starlark.Value
+		var key string
+		var def starlark.Value = starlark.None
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "key", &key, "default?", &def); err != nil {
+			return nil, err
+		}
+		v, found, err := lookup(resource, []starlark.Value{starlark.String("metadata"), starlark.String(field), starlark.String(key)})
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return def, nil
+		}
+		return v, nil
+	}
+}
+
+// setMetadataField returns the builtin setting a label or an annotation
+func setMetadataField(field string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var resource starlark.Value
+		var key, value string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "key", &key, "value", &value); err != nil {
+			return nil, err
+		}
+		if _, err := resourceDict(b, resource); err != nil {
+			return nil, err
+		}
+		if field == "annotations" && key == fn.UpstreamIdentifier {
+			return nil, fmt.Errorf("%s: the %s annotation is managed by kpt", b.Name(), key)
+		}
+		keys := []starlark.Value{starlark.String("metadata"), starlark.String(field), starlark.String(key)}
+		if err := setField(b, resource, keys, starlark.String(value)); err != nil {
+			return nil, err
+		}
+		return starlark.None, nil
+	}
+}
+
+// deleteMetadataField returns the builtin deleting a label or an annotation
+func deleteMetadataField(field string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var resource starlark.Value
+		var key string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, "resource", &resource, "key", &key); err != nil {
+			return nil, err
+		}
+		return deleteField(resource, []starlark.Value{starlark.String("metadata"), starlark.String(field), starlark.String(key)})
+	}
+}
+
+// clusterScoped returns whether the resource is cluster scoped, the scope of
+// the unknown kinds is guessed from their namespace like fn.KubeObject
+func clusterScoped(resource *starlark.Dict) bool {
+	tm := yaml.TypeMeta{APIVersion: getString(resource, "apiVersion"), Kind: getString(resource, "kind")}
+	if namespaced, found := openapi.IsNamespaceScoped(tm); found {
+		return !namespaced
+	}
+	_, found, _ := lookup(resource, []starlark.Value{starlark.String("metadata"), starlark.String("namespace")})
+	return !found
+}
+
+func isClusterScoped(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &resource); err != nil {
+		return nil, err
+	}
+	d, err := resourceDict(b, resource)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.Bool(clusterScoped(d)), nil
+}
+
+// setNamespace sets the namespace of a namespace scoped resource, and returns
+// whether the namespace was set
+func setNamespace(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
+	var namespace string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &resource, &namespace); err != nil {
+		return nil, err
+	}
+	d, err := resourceDict(b, resource)
+	if err != nil {
+		return nil, err
+	}
+	tm := yaml.TypeMeta{APIVersion: getString(d, "apiVersion"), Kind: getString(d, "kind")}
+	if namespaced, found := openapi.IsNamespaceScoped(tm); found && !namespaced {
+		return starlark.False, nil
+	}
+	keys := []starlark.Value{starlark.String("metadata"), starlark.String("namespace")}
+	if err := setField(b, d, keys, starlark.String(namespace)); err != nil {
+		return nil, err
+	}
+	return starlark.True, nil
+}
+
+// originID returns the id of the upstream origin of the resource in the format
+// of the upstream-identifier annotation of kpt, group|kind|namespace|name
+func originID(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &resource); err != nil {
+		return nil, err
+	}
+	d, err := resourceDict(b, resource)
+	if err != nil {
+		return nil, err
+	}
+	if upstream := getString(d, "metadata", "annotations", fn.UpstreamIdentifier); upstream != "" {
+		return starlark.String(upstream), nil
+	}
+	group, _ := fn.ParseGroupVersion(getString(d, "apiVersion"))
+	namespace := getString(d, "metadata", "namespace")
+	if namespace == "" {
+		namespace = fn.UnknownNamespace
+		if !clusterScoped(d) {
+			namespace = fn.DefaultNamespace
+		}
+	}
+	id := fn.ResourceIdentifier{
+		Group:     group,
+		Kind:      getString(d, "kind"),
+		Namespace: namespace,
+		Name:      getString(d, "metadata", "name"),
+	}
+	return starlark.String(id.String()), nil
+}
+
+// matchLabels returns whether the labels of the resource match the selector,
+// which is either a string e.g. "app=nginx,tier in (frontend)", or a label
+// selector dict with matchLabels and matchExpressions
+func matchLabels(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource, selector starlark.Value
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &resource, &selector); err != nil {
+		return nil, err
+	}
+	d, err := resourceDict(b, resource)
+	if err != nil {
+		return nil, err
+	}
+	var sel labels.Selector
+	switch s := selector.(type) {
+	case starlark.String:
+		if sel, err = labels.Parse(string(s)); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+	case *starlark.Dict:
+		v, err := util.Unmarshal(s)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		ls := &metav1.LabelSelector{}
+		if err := json.Unmarshal(data, ls); err != nil {
+			return nil, fmt.Errorf("%s: invalid label selector: %w", b.Name(), err)
+		}
+		if sel, err = metav1.LabelSelectorAsSelector(ls); err != nil {
+			return nil, fmt.Errorf("%s: %w", b.Name(), err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: selector must be a string or a dict, got %s", b.Name(), selector.Type())
+	}
+
+	set := labels.Set{}
+	l, _, err := lookup(d, []starlark.Value{starlark.String("metadata"), starlark.String("labels")})
+	if err != nil {
+		return nil, err
+	}
+	if ld, ok := l.(*starlark.Dict); ok {
+		for _, item := range ld.Items() {
+			k, kok := item[0].(starlark.String)
+			v, vok := item[1].(starlark.String)
+			if kok && vok {
+				set[string(k)] = string(v)
+			}
+		}
+	}
+	return starlark.Bool(sel.Matches(set)), nil
+}
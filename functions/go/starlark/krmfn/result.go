@@ -0,0 +1,110 @@
+package krmfn
+
+import (
+	"fmt"
+	"strconv"
+
+	kstarlark "github.com/kptdev/krm-functions-catalog/functions/go/starlark/third_party/sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+)
+
+// result appends a result to the results of the resource list, the resource
+// reference and the file of the result are read from the resource
+func result(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var severity, message, field string
+	var resource starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs,
+		"severity", &severity, "message", &message, "resource?", &resource, "field?", &field); err != nil {
+		return nil, err
+	}
+	switch fn.Severity(severity) {
+	case fn.Error, fn.Warning, fn.Info:
+	default:
+		return nil, fmt.Errorf("%s: severity must be one of %s, %s or %s, got %q", b.Name(), fn.Error, fn.Warning, fn.Info, severity)
+	}
+
+	r := starlark.NewDict(5)
+	if err := r.SetKey(starlark.String("severity"), starlark.String(severity)); err != nil {
+		return nil, err
+	}
+	if err := r.SetKey(starlark.String("message"), starlark.String(message)); err != nil {
+		return nil, err
+	}
+	if resource != starlark.None {
+		d, err := resourceDict(b, resource)
+		if err != nil {
+			return nil, err
+		}
+		if err := setResourceRef(r, d); err != nil {
+			return nil, err
+		}
+	}
+	if field != "" {
+		f := starlark.NewDict(1)
+		if err := f.SetKey(starlark.String("path"), starlark.String(field)); err != nil {
+			return nil, err
+		}
+		if err := r.SetKey(starlark.String("field"), f); err != nil {
+			return nil, err
+		}
+	}
+
+	rl, ok := thread.Local(kstarlark.ResourceListLocal).(starlark.Value)
+	if !ok {
+		return nil, fmt.Errorf("%s: the script has no resource list", b.Name())
+	}
+	results, err := kstarlark.ResultList(rl)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	if err := results.Append(r); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return starlark.None, nil
+}
+
+// setResourceRef sets the resourceRef and the file of the result to the ones
+// of the resource
+func setResourceRef(r, resource *starlark.Dict) error {
+	ref := starlark.NewDict(4)
+	for _, f := range []struct {
+		key  string
+		path []string
+	}{
+		{"apiVersion", []string{"apiVersion"}},
+		{"kind", []string{"kind"}},
+		{"name", []string{"metadata", "name"}},
+		{"namespace", []string{"metadata", "namespace"}},
+	} {
+		if v := getString(resource, f.path...); v != "" {
+			if err := ref.SetKey(starlark.String(f.key), starlark.String(v)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.SetKey(starlark.String("resourceRef"), ref); err != nil {
+		return err
+	}
+
+	path := getString(resource, "metadata", "annotations", kioutil.PathAnnotation)
+	index := getString(resource, "metadata", "annotations", kioutil.IndexAnnotation)
+	if path == "" {
+		path = getString(resource, "metadata", "annotations", kioutil.LegacyPathAnnotation)
+		index = getString(resource, "metadata", "annotations", kioutil.LegacyIndexAnnotation)
+	}
+	if path == "" {
+		return nil
+	}
+	file := starlark.NewDict(2)
+	if err := file.SetKey(starlark.String("path"), starlark.String(path)); err != nil {
+		return err
+	}
+	if i, err := strconv.Atoi(index); err == nil {
+		if err := file.SetKey(starlark.String("index"), starlark.MakeInt(i)); err != nil {
+			return err
+		}
+	}
+	return r.SetKey(starlark.String("file"), file)
+}
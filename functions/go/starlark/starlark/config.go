@@ -2,9 +2,12 @@ package starlark
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/kptdev/krm-functions-catalog/functions/go/starlark/krmfn"
 	"github.com/kptdev/krm-functions-catalog/functions/go/starlark/third_party/sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
 	"github.com/kptdev/krm-functions-sdk/go/fn"
+	gostarlark "go.starlark.net/starlark"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/kustomize/kyaml/yaml"
@@ -22,6 +25,9 @@ const (
 	sourceKey = "source"
 
 	defaultProgramName = "starlark-function-run"
+
+	defaultMaxExecutionSteps = 10000000
+	defaultTimeout           = 30 * time.Second
 )
 
 type StarlarkRun struct {
@@ -30,6 +36,17 @@ type StarlarkRun struct {
 	Source string `json:"source" yaml:"source"`
 	// Params are the parameters in key-value pairs format.
 	Params map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	// AllowNetwork allows the script to load the libraries accessing the
+	// network. Defaults to false.
+	AllowNetwork bool `json:"allowNetwork,omitempty" yaml:"allowNetwork,omitempty"`
+	// MaxExecutionSteps is the maximum number of execution steps of the
+	// script. Defaults to 10000000.
+	MaxExecutionSteps uint64 `json:"maxExecutionSteps,omitempty" yaml:"maxExecutionSteps,omitempty"`
+	// Timeout is the maximum duration of the script, e.g. `1m`. Defaults to
+	// `30s`.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	timeout time.Duration
 }
 
 func (sr *StarlarkRun) Config(fnCfg *fn.KubeObject) error {
@@ -66,10 +83,24 @@ func (sr *StarlarkRun) Config(fnCfg *fn.KubeObject) error {
 	if sr.Name == "" {
 		sr.Name = defaultProgramName
 	}
+	if sr.MaxExecutionSteps == 0 {
+		sr.MaxExecutionSteps = defaultMaxExecutionSteps
+	}
+	sr.timeout = defaultTimeout
 	// Validation
 	if sr.Source == "" {
 		return fmt.Errorf("`source` must not be empty")
 	}
+	if sr.Timeout != "" {
+		timeout, err := time.ParseDuration(sr.Timeout)
+		if err != nil {
+			return fmt.Errorf("`timeout` must be a duration, e.g. `1m`: %w", err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("`timeout` must be positive, but we got: %v", sr.Timeout)
+		}
+		sr.timeout = timeout
+	}
 	return nil
 }
 
@@ -93,6 +124,14 @@ func (sr *StarlarkRun) Transform(rl *fn.ResourceList) error {
 		Name:           sr.Name,
 		Program:        sr.Source,
 		FunctionConfig: fcRN,
+		Options: starlark.Options{
+			AllowNetwork:      sr.AllowNetwork,
+			MaxExecutionSteps: sr.MaxExecutionSteps,
+			Timeout:           sr.timeout,
+			Modules: map[string]gostarlark.StringDict{
+				krmfn.ModuleName: {"krmfn": krmfn.Module},
+			},
+		},
 	}
 	transformedNodes, err := starFltr.Filter(nodes)
 	if err != nil {
@@ -107,5 +146,37 @@ func (sr *StarlarkRun) Transform(rl *fn.ResourceList) error {
 		transformedObjects = append(transformedObjects, obj)
 	}
 	rl.Items = transformedObjects
+
+	results, err := parseResults(starFltr.Results)
+	if err != nil {
+		return err
+	}
+	rl.Results = append(rl.Results, results...)
 	return nil
 }
+
+// parseResults parses the results written by the starlark program to
+// ctx.results
+func parseResults(rn *yaml.RNode) (fn.Results, error) {
+	if rn == nil {
+		return nil, nil
+	}
+	var results fn.Results
+	if err := rn.YNode().Decode(&results); err != nil {
+		return nil, fmt.Errorf("`ctx.results` must be a list of results: %w", err)
+	}
+	for i, r := range results {
+		if r == nil || r.Message == "" {
+			return nil, fmt.Errorf("`ctx.results[%d]` must have a message", i)
+		}
+		switch r.Severity {
+		case fn.Error, fn.Warning, fn.Info:
+		case "":
+			r.Severity = fn.Info
+		default:
+			return nil, fmt.Errorf("`ctx.results[%d]` severity must be one of %s, %s or %s, but we got: %s",
+				i, fn.Error, fn.Warning, fn.Info, r.Severity)
+		}
+	}
+	return results, nil
+}
@@ -36,6 +36,43 @@ metadata:
 `,
 			expectErrMsg: "`source` must not be empty",
 		},
+		{
+			name: "StarlarkRun with limits",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: StarlarkRun
+metadata:
+  name: my-star-fn
+allowNetwork: true
+maxExecutionSteps: 1000
+timeout: 1m
+source: |
+  load("http.star", "http")
+`,
+		},
+		{
+			name: "StarlarkRun invalid timeout",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: StarlarkRun
+metadata:
+  name: my-star-fn
+timeout: ten seconds
+source: |
+  print("hello")
+`,
+			expectErrMsg: "`timeout` must be a duration, e.g. `1m`",
+		},
+		{
+			name: "StarlarkRun negative timeout",
+			config: `apiVersion: fn.kpt.dev/v1alpha1
+kind: StarlarkRun
+metadata:
+  name: my-star-fn
+timeout: -1s
+source: |
+  print("hello")
+`,
+			expectErrMsg: "`timeout` must be positive, but we got: -1s",
+		},
 		{
 			name: "valid ConfigMap",
 			config: `apiVersion: v1
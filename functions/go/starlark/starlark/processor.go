@@ -21,5 +21,9 @@ func Process(resourceList *fn.ResourceList) (bool, error) {
 		}
 		return false, nil
 	}
+	// an error result written by the script fails the function
+	if resourceList.Results.ExitCode() != 0 {
+		return false, nil
+	}
 	return true, nil
 }
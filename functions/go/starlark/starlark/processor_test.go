@@ -0,0 +1,242 @@
+package starlark
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kptdev/krm-functions-sdk/go/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessResults(t *testing.T) {
+	const input = `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items:
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata:
+    name: nginx
+    namespace: default
+    annotations:
+      internal.config.kubernetes.io/path: deployment.yaml
+      internal.config.kubernetes.io/index: '0'
+  spec:
+    replicas: 1
+functionConfig:
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: StarlarkRun
+  metadata:
+    name: results
+  source: |
+%s
+`
+	testcases := []struct {
+		name          string
+		source        string
+		expectSuccess bool
+		expectResults fn.Results
+	}{
+		{
+			name: "ctx.results",
+			source: `
+    ctx.results.append({"severity": "info", "message": "checked the replicas"})
+    for r in ctx.resource_list["items"]:
+      ctx.results.append({
+        "severity": "warning",
+        "message": "too few replicas",
+        "resourceRef": {"apiVersion": r["apiVersion"], "kind": r["kind"], "name": r["metadata"]["name"], "namespace": r["metadata"]["namespace"]},
+        "field": {"path": "spec.replicas", "currentValue": r["spec"]["replicas"], "proposedValue": 3},
+        "file": {"path": "deployment.yaml", "index": 0},
+      })
+`,
+			expectSuccess: true,
+			expectResults: fn.Results{
+				{
+					Severity: fn.Info,
+					Message:  "checked the replicas",
+				},
+				{
+					Severity: fn.Warning,
+					Message:  "too few replicas",
+					ResourceRef: &fn.ResourceRef{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "nginx",
+						Namespace:  "default",
+					},
+					Field: &fn.Field{Path: "spec.replicas", CurrentValue: 1, ProposedValue: 3},
+					File:  &fn.File{Path: "deployment.yaml"},
+				},
+			},
+		},
+		{
+			name: "resource_list results",
+			source: `
+    ctx.resource_list["results"] = [{"message": "no severity"}]
+`,
+			expectSuccess: true,
+			expectResults: fn.Results{
+				{
+					Severity: fn.Info,
+					Message:  "no severity",
+				},
+			},
+		},
+		{
+			name: "krmfn.result",
+			source: `
+    load("krmfn.star", "krmfn")
+    for r in ctx.resource_list["items"]:
+      krmfn.result("error", "replicas must be at least 3", r, "spec.replicas")
+`,
+			expectSuccess: false,
+			expectResults: fn.Results{
+				{
+					Severity: fn.Error,
+					Message:  "replicas must be at least 3",
+					ResourceRef: &fn.ResourceRef{
+						APIVersion: "apps/v1",
+						Kind:       "Deployment",
+						Name:       "nginx",
+						Namespace:  "default",
+					},
+					Field: &fn.Field{Path: "spec.replicas"},
+					File:  &fn.File{Path: "deployment.yaml"},
+				},
+			},
+		},
+		{
+			name: "invalid severity",
+			source: `
+    ctx.results.append({"severity": "fatal", "message": "invalid"})
+`,
+			expectSuccess: false,
+			expectResults: fn.Results{
+				{
+					Severity: fn.Error,
+					Message:  "`ctx.results[0]` severity must be one of error, warning or info, but we got: fatal",
+				},
+			},
+		},
+		{
+			name: "missing message",
+			source: `
+    ctx.results.append({"severity": "info"})
+`,
+			expectSuccess: false,
+			expectResults: fn.Results{
+				{
+					Severity: fn.Error,
+					Message:  "`ctx.results[0]` must have a message",
+				},
+			},
+		},
+		{
+			name: "script failure",
+			source: `
+    ctx.results.append({"severity": "info", "message": "dropped"})
+    fail("invalid package")
+`,
+			expectSuccess: false,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(fmt.Sprintf(input, tc.source)))
+			assert.NoError(t, err)
+			success, err := Process(rl)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectSuccess, success)
+			if tc.expectResults == nil {
+				assert.Len(t, rl.Results, 1)
+				assert.Equal(t, fn.Error, rl.Results[0].Severity)
+				assert.Contains(t, rl.Results[0].Message, "invalid package")
+				return
+			}
+			assert.Equal(t, tc.expectResults, rl.Results)
+		})
+	}
+}
+
+func TestProcessLimits(t *testing.T) {
+	const input = `apiVersion: config.kubernetes.io/v1
+kind: ResourceList
+items: []
+functionConfig:
+  apiVersion: fn.kpt.dev/v1alpha1
+  kind: StarlarkRun
+  metadata:
+    name: limits
+%s
+  source: |
+%s
+`
+	testcases := []struct {
+		name         string
+		options      string
+		source       string
+		expectErrMsg string
+	}{
+		{
+			name: "network library",
+			source: `
+    load("http.star", "http")
+`,
+			expectErrMsg: "cannot load http.star: the network access isn't allowed",
+		},
+		{
+			name:    "network library allowed",
+			options: "  allowNetwork: true",
+			source: `
+    load("http.star", "http")
+`,
+		},
+		{
+			name: "default execution steps",
+			source: `
+    while True:
+      pass
+`,
+			expectErrMsg: "Starlark computation cancelled: exceeded the limit of 10000000 execution steps",
+		},
+		{
+			name:    "execution steps",
+			options: "  maxExecutionSteps: 1000",
+			source: `
+    for i in range(1000):
+      pass
+`,
+			expectErrMsg: "Starlark computation cancelled: exceeded the limit of 1000 execution steps",
+		},
+		{
+			name: "timeout",
+			options: `  maxExecutionSteps: 1000000000000
+  timeout: 100ms`,
+			source: `
+    while True:
+      pass
+`,
+			expectErrMsg: "Starlark computation cancelled: exceeded the timeout of 100ms",
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rl, err := fn.ParseResourceList([]byte(fmt.Sprintf(input, tc.options, tc.source)))
+			assert.NoError(t, err)
+			success, err := Process(rl)
+			assert.NoError(t, err)
+			if tc.expectErrMsg == "" {
+				assert.True(t, success)
+				assert.Empty(t, rl.Results)
+				return
+			}
+			assert.False(t, success)
+			if assert.Len(t, rl.Results, 1) {
+				assert.Equal(t, fn.Error, rl.Results[0].Severity)
+				assert.Contains(t, rl.Results[0].Message, tc.expectErrMsg)
+			}
+		})
+	}
+}
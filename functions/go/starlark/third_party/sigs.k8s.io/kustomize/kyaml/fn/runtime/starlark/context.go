@@ -19,6 +19,7 @@ import (
 
 type Context struct {
 	resourceList starlark.Value
+	results      starlark.Value
 }
 
 func (c *Context) predeclared() (starlark.StringDict, error) {
@@ -28,6 +29,7 @@ func (c *Context) predeclared() (starlark.StringDict, error) {
 	}
 	dict := starlark.StringDict{
 		"resource_list": c.resourceList,
+		"results":       c.results,
 		"open_api":      &LazyInitializationOpenapi{},
 		"environment":   e,
 	}
@@ -1,7 +1,6 @@
 package starlark
 
 import (
-	"github.com/kptdev/krm-functions-catalog/functions/go/starlark/krmfn"
 	"github.com/qri-io/starlib/bsoup"
 	"github.com/qri-io/starlib/encoding/base64"
 	"github.com/qri-io/starlib/encoding/csv"
@@ -17,10 +16,27 @@ import (
 	"github.com/qri-io/starlib/xlsx"
 	"github.com/qri-io/starlib/zipfile"
 	"go.starlark.net/starlark"
+	"sigs.k8s.io/kustomize/kyaml/errors"
 )
 
-// load loads starlark libraries from https://github.com/qri-io/starlib#packages and from
-// our own custom libraries.
+// networkModules are the libraries accessing the network
+var networkModules = map[string]bool{
+	http.ModuleName: true,
+}
+
+// load loads the libraries of the function and the starlark libraries, the
+// ones accessing the network are refused unless the network access is allowed
+func (o Options) load(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	if m, found := o.Modules[module]; found {
+		return m, nil
+	}
+	if networkModules[module] && !o.AllowNetwork {
+		return nil, errors.Errorf("the network access isn't allowed")
+	}
+	return load(thread, module)
+}
+
+// load loads starlark libraries from https://github.com/qri-io/starlib#packages.
 func load(_ *starlark.Thread, module string) (starlark.StringDict, error) {
 	switch module {
 	case bsoup.ModuleName:
@@ -51,8 +67,6 @@ func load(_ *starlark.Thread, module string) (starlark.StringDict, error) {
 		return xlsx.LoadModule()
 	case zipfile.ModuleName:
 		return zipfile.LoadModule()
-	case krmfn.ModuleName:
-		return starlark.StringDict{"krmfn": krmfn.Module}, nil
 	}
 	return nil, nil
 }
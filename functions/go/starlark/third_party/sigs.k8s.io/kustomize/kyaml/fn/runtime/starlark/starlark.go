@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/qri-io/starlib/util"
 	"go.starlark.net/resolve"
@@ -33,9 +34,34 @@ type Filter struct {
 	// Path is the path to a starlark program to read and run
 	Path string
 
+	// Options are the limits of the starlark program, the program can't be
+	// fetched from URL unless the network access is allowed
+	Options Options
+
 	runtimeutil.FunctionFilter
 }
 
+// Options are the limits and the libraries of a starlark program, the zero value
+// doesn't allow the network access and doesn't limit the execution
+type Options struct {
+	// AllowNetwork allows the program to load the libraries accessing the
+	// network
+	AllowNetwork bool
+	// MaxExecutionSteps is the maximum number of execution steps of the
+	// program, 0 means no limit
+	MaxExecutionSteps uint64
+	// Timeout is the maximum wall-clock duration of the program, 0 means no
+	// limit
+	Timeout time.Duration
+	// Modules are the libraries of the function loaded by name in addition
+	// to the starlib ones
+	Modules map[string]starlark.StringDict
+}
+
+// ResourceListLocal is the thread local of the resource list of the program,
+// the libraries append their results to it
+const ResourceListLocal = "resource_list"
+
 func (sf *Filter) String() string {
 	return fmt.Sprintf(
 		"name: %v path: %v url: %v program: %v", sf.Name, sf.Path, sf.URL, sf.Program)
@@ -69,6 +95,9 @@ func (sf *Filter) setup() error {
 
 	// read the program from a URL
 	if sf.URL != "" {
+		if !sf.Options.AllowNetwork {
+			return errors.Errorf("Filter URL requires the network access, which isn't allowed")
+		}
 		err := func() error {
 			resp, err := http.Get(sf.URL)
 			if err != nil {
@@ -102,7 +131,7 @@ func (sf *Filter) Run(reader io.Reader, writer io.Writer) error {
 		return errors.Wrap(err)
 	}
 
-	err = runStarlark(sf.Name, sf.Program, value)
+	err = runStarlark(sf.Name, sf.Program, value, sf.Options)
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -111,7 +140,7 @@ func (sf *Filter) Run(reader io.Reader, writer io.Writer) error {
 }
 
 // runStarlark runs the starlark script
-func runStarlark(name, starlarkProgram string, resourceList starlark.Value) error {
+func runStarlark(name, starlarkProgram string, resourceList starlark.Value, opts Options) error {
 	// Enabled some non-standard starlark features (https://pkg.go.dev/go.starlark.net/resolve#pkg-variables).
 	// LoadBindsGlobally is not enabled, since it has been deprecated.
 	//nolint:staticcheck
@@ -122,9 +151,26 @@ func runStarlark(name, starlarkProgram string, resourceList starlark.Value) erro
 	resolve.AllowRecursion = true
 
 	// run the starlark as program as transformation function
-	thread := &starlark.Thread{Name: name, Load: load}
+	thread := &starlark.Thread{Name: name, Load: opts.load}
+	if opts.MaxExecutionSteps > 0 {
+		thread.SetMaxExecutionSteps(opts.MaxExecutionSteps)
+		thread.OnMaxSteps = func(thread *starlark.Thread) {
+			thread.Cancel(fmt.Sprintf("exceeded the limit of %d execution steps", opts.MaxExecutionSteps))
+		}
+	}
+	if opts.Timeout > 0 {
+		timer := time.AfterFunc(opts.Timeout, func() {
+			thread.Cancel(fmt.Sprintf("exceeded the timeout of %s", opts.Timeout))
+		})
+		defer timer.Stop()
+	}
+	thread.SetLocal(ResourceListLocal, resourceList)
 
-	ctx := &Context{resourceList: resourceList}
+	results, err := ResultList(resourceList)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	ctx := &Context{resourceList: resourceList, results: results}
 	pd, err := ctx.predeclared()
 	if err != nil {
 		return errors.Wrap(err)
@@ -137,6 +183,31 @@ func runStarlark(name, starlarkProgram string, resourceList starlark.Value) erro
 	return nil
 }
 
+// ResultList returns the results list of the resource list, the list is
+// created if it's missing so that ctx.results is an alias of it
+func ResultList(resourceList starlark.Value) (*starlark.List, error) {
+	rl, ok := resourceList.(*starlark.Dict)
+	if !ok {
+		return nil, errors.Errorf("resource list must be a dict, got %s", resourceList.Type())
+	}
+	v, found, err := rl.Get(starlark.String("results"))
+	if err != nil {
+		return nil, err
+	}
+	if !found || v == starlark.None {
+		results := starlark.NewList(nil)
+		if err := rl.SetKey(starlark.String("results"), results); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	results, ok := v.(*starlark.List)
+	if !ok {
+		return nil, errors.Errorf("results of the resource list must be a list, got %s", v.Type())
+	}
+	return results, nil
+}
+
 // inputToResourceList transforms input into a starlark.Value
 func (sf *Filter) readResourceList(reader io.Reader) (starlark.Value, error) {
 	// read and parse the inputs
@@ -218,6 +289,11 @@ type SimpleFilter struct {
 	Program string
 	// FunctionConfig is the functionConfig for the function.
 	FunctionConfig *yaml.RNode
+	// Options are the limits of the starlark program.
+	Options Options
+	// Results are the results written by the program to ctx.results, they
+	// are set by Filter.
+	Results *yaml.RNode
 }
 
 func (sf *SimpleFilter) String() string {
@@ -235,7 +311,7 @@ func (sf *SimpleFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 		return nil, errors.Wrap(err)
 	}
 
-	err = runStarlark(sf.Name, sf.Program, value)
+	err = runStarlark(sf.Name, sf.Program, value, sf.Options)
 	if err != nil {
 		return nil, errors.Wrap(err)
 	}
@@ -245,7 +321,14 @@ func (sf *SimpleFilter) Filter(nodes []*yaml.RNode) ([]*yaml.RNode, error) {
 		return nil, errors.Wrap(err)
 	}
 	updatedNodes, _, err := UnwrapResources(rn)
-	return updatedNodes, err
+	if err != nil {
+		return nil, err
+	}
+	sf.Results, err = rn.Pipe(yaml.Lookup("results"))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return updatedNodes, nil
 }
 
 // WrapResources wraps resources and an optional functionConfig in a resourceList
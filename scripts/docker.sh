@@ -61,6 +61,15 @@ function docker_build {
     echo "Setting build context to ${function_dir}"
   fi
 
+  # the functions replacing the modules shared in functions/go/internal are built
+  # from the parent directory, so that the shared modules are in the build context
+  if grep -qs '=> \.\./internal' "${function_dir}/go.mod"; then
+    [[ -f "${override_dockerfile}" ]] || dockerfile="${repo_base}/build/docker/go/Dockerfile.shared"
+    build_args+=(--build-arg "FUNCTION_DIR=${name}")
+    function_dir=$(dirname "${function_dir}")
+    echo "Setting build context to ${function_dir} with Dockerfile ${dockerfile}"
+  fi
+
   echo "building ${CR_REGISTRY}/${name}:${tag}"
 
   case "${action}" in
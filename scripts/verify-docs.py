@@ -33,7 +33,7 @@ import subprocess
 import yaml
 
 metadata_filename = 'metadata.yaml'
-directories_to_skip = ['_template', 'dist', 'node_modules']
+directories_to_skip = ['_template', 'dist', 'node_modules', 'internal']
 examples_directory = 'examples'
 functions_directory = 'functions'
 lang_dirs = ['go', 'ts']